@@ -0,0 +1,85 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/kitaisreal/paw/internal/stats"
+)
+
+const (
+	adaptiveDefaultMinRuns = 5
+	// Stability is checked every adaptiveStabilityCheckRuns runs after min runs, because bootstrap confidence
+	// interval is too expensive to compute after each run
+	adaptiveStabilityCheckRuns = 5
+
+	adaptiveStopReasonStable     = "stable"
+	adaptiveStopReasonMaxRuns    = "max_runs"
+	adaptiveStopReasonTimeBudget = "time_budget"
+)
+
+// getAdaptiveStopReason returns reason to stop adaptive measure runs after executionTimes are measured during
+// elapsed time, or empty string if measure runs should continue. Stability is checked after min runs and then
+// every adaptiveStabilityCheckRuns runs.
+func getAdaptiveStopReason(executionTimes []driver.ExecutionTime,
+	adaptiveSettings config.AdaptiveSettings,
+	elapsed time.Duration,
+) string {
+	runs := uint64(len(executionTimes))
+
+	minRuns := adaptiveSettings.MinRuns
+	if minRuns == 0 {
+		minRuns = adaptiveDefaultMinRuns
+	}
+
+	if runs >= adaptiveSettings.MaxRuns {
+		return adaptiveStopReasonMaxRuns
+	}
+
+	if runs < minRuns {
+		return ""
+	}
+
+	if (runs-minRuns)%adaptiveStabilityCheckRuns == 0 && isMeasureStable(executionTimes, adaptiveSettings) {
+		return adaptiveStopReasonStable
+	}
+
+	timeBudget := time.Duration(adaptiveSettings.TimeBudgetSeconds * float64(time.Second))
+	if timeBudget > 0 && elapsed >= timeBudget {
+		return adaptiveStopReasonTimeBudget
+	}
+
+	return ""
+}
+
+// isMeasureStable returns true if all specified targets are reached. Server durations are checked if driver reports
+// them, otherwise client durations are checked.
+func isMeasureStable(executionTimes []driver.ExecutionTime, adaptiveSettings config.AdaptiveSettings) bool {
+	if adaptiveSettings.TargetRelativeCIWidth == 0 && adaptiveSettings.TargetCV == 0 {
+		return false
+	}
+
+	hasServerDuration := stats.HasServerDurations(executionTimes)
+
+	values := make([]float64, len(executionTimes))
+	for i, executionTime := range executionTimes {
+		if hasServerDuration {
+			values[i] = float64(executionTime.ServerDuration)
+		} else {
+			values[i] = float64(executionTime.ClientDuration)
+		}
+	}
+
+	// Coefficient of variation is checked first, because it is much cheaper than bootstrap confidence interval
+	if adaptiveSettings.TargetCV > 0 && stats.GetCoefficientOfVariation(values) > adaptiveSettings.TargetCV {
+		return false
+	}
+
+	if adaptiveSettings.TargetRelativeCIWidth > 0 &&
+		stats.GetRelativeMedianConfidenceIntervalWidth(values) > adaptiveSettings.TargetRelativeCIWidth {
+		return false
+	}
+
+	return true
+}
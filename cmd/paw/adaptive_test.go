@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAdaptiveStopReason(t *testing.T) {
+	adaptiveSettings := config.AdaptiveSettings{MinRuns: 3, MaxRuns: 20, TargetCV: 50}
+
+	executionTimes := []driver.ExecutionTime{}
+	stopReasons := []string{}
+
+	for range adaptiveSettings.MaxRuns {
+		executionTimes = append(executionTimes, driver.ExecutionTime{ClientDuration: time.Millisecond})
+		stopReasons = append(stopReasons, getAdaptiveStopReason(executionTimes, adaptiveSettings, 0))
+	}
+
+	// Stability is checked after min runs and then every 5 runs
+	for i, stopReason := range stopReasons[:len(stopReasons)-1] {
+		runs := uint64(i + 1)
+		if runs == 3 || runs == 8 || runs == 13 || runs == 18 {
+			require.Equal(t, adaptiveStopReasonStable, stopReason, runs)
+		} else {
+			require.Empty(t, stopReason, runs)
+		}
+	}
+
+	require.Equal(t, adaptiveStopReasonMaxRuns, stopReasons[len(stopReasons)-1])
+
+	adaptiveSettings.TargetCV = 0
+	adaptiveSettings.TimeBudgetSeconds = 1
+	require.Empty(t, getAdaptiveStopReason(executionTimes[:4], adaptiveSettings, 0))
+
+	stopReason := getAdaptiveStopReason(executionTimes[:4], adaptiveSettings, time.Second)
+	require.Equal(t, adaptiveStopReasonTimeBudget, stopReason)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kitaisreal/paw/internal/collector"
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/kitaisreal/paw/internal/logger"
+)
+
+// recordColdRuns runs query cold runs, before each run OS and engine caches are dropped using configured hooks.
+func recordColdRuns(ctx context.Context,
+	drv driver.Driver,
+	coldSettings config.ColdSettings,
+	queryNumber int,
+	query string,
+) ([]driver.ExecutionTime, error) {
+	executionTimes := []driver.ExecutionTime{}
+
+	logger.Log.Debugf("Running %v query '%v' cold runs %v", queryNumber, query, coldSettings.Runs)
+
+	for run := uint64(0); run < coldSettings.Runs; run++ {
+		if err := dropCaches(ctx, drv, coldSettings); err != nil {
+			return executionTimes, err
+		}
+
+		executionTime, err := drv.Run(ctx, query)
+		if err != nil {
+			return executionTimes, err
+		}
+
+		executionTimes = append(executionTimes, executionTime)
+	}
+
+	logger.Log.Debugf("Finished running %v query '%v' cold runs %v", queryNumber, query, coldSettings.Runs)
+
+	return executionTimes, nil
+}
+
+func dropCaches(ctx context.Context, drv driver.Driver, coldSettings config.ColdSettings) error {
+	for _, command := range coldSettings.DropCacheCommands {
+		var output bytes.Buffer
+
+		// Drop cache command is run in its own process group, so processes started by shell are killed on cancel
+		cmd := collector.NewCommand(ctx, "sh", "-c", command)
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("drop cache command '%s' error: %w: %s", command, err, strings.TrimSpace(output.String()))
+		}
+	}
+
+	for _, query := range coldSettings.DropCacheQueries {
+		if _, err := drv.Run(ctx, query); err != nil {
+			return fmt.Errorf("drop cache query '%s' error: %w", query, err)
+		}
+	}
+
+	return nil
+}
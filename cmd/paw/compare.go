@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/logger"
+	"github.com/kitaisreal/paw/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+const (
+	compareDefaultSignificanceLevel = 0.05
+	compareDefaultMaxRegression     = 5
+)
+
+// CompareQueryResult compares server durations if both LHS and RHS have them, otherwise client durations. Failed
+// queries have partial execution times, so they are not compared and their change is empty.
+type CompareQueryResult struct {
+	QueryRecordPair QueryRecordPairWithStats
+	LHSMedian       float64
+	RHSMedian       float64
+	Comparison      stats.Comparison
+	RelativeDiff    float64
+	MaxRegression   float64
+	Allowed         bool
+	Change          stats.Change
+}
+
+// IsFailed returns true if query regression is significant, larger than query max regression or query started
+// failing, and query is not allowed to regress.
+func (r CompareQueryResult) IsFailed() bool {
+	return (r.Change == stats.ChangeRegression || r.QueryRecordPair.IsStartedFailing()) && !r.Allowed
+}
+
+func Compare(cmd *cobra.Command, args []string) {
+	lhsFolder := convertPathToFolder(args[0])
+	rhsFolder := convertPathToFolder(args[1])
+
+	compareSettings := buildCompareSettings(cmd)
+
+	lhsRecords, err := parseTestFolder(lhsFolder)
+	if err != nil {
+		logger.Log.Errorf("Failed to parse lhs test folder %s: %v", lhsFolder, err)
+		os.Exit(1)
+	}
+
+	rhsRecords, err := parseTestFolder(rhsFolder)
+	if err != nil {
+		logger.Log.Errorf("Failed to parse rhs test folder %s: %v", rhsFolder, err)
+		os.Exit(1)
+	}
+
+	queryRecordPairs := buildQueryRecordsDiff(lhsRecords, rhsRecords)
+
+	queryMismatchQueryIDs := []string{}
+	for _, queryRecordPair := range queryRecordPairs {
+		if queryRecordPair.IsQueryMismatch() {
+			queryMismatchQueryIDs = append(queryMismatchQueryIDs, queryRecordPair.LHS.Record.ID)
+		}
+	}
+
+	if len(queryMismatchQueryIDs) > 0 {
+		logger.Log.Warnf("Queries %v text differs between lhs and rhs", queryMismatchQueryIDs)
+	}
+
+	compareResults := compareQueryRecords(queryRecordPairs, compareSettings)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Query\tLHS Median (ms)\tRHS Median (ms)\tDiff\t95% CI\tP-Value\tMax Regression\tStatus")
+
+	failedQueries := []string{}
+
+	for _, compareResult := range compareResults {
+		queryRecordPair := compareResult.QueryRecordPair
+		confidenceInterval := compareResult.Comparison.RelativeMedianDiffConfidenceInterval
+
+		status := string(compareResult.Change)
+		switch {
+		case compareResult.IsFailed() && queryRecordPair.IsStartedFailing():
+			status = "FAIL (started failing)"
+		case compareResult.IsFailed():
+			status = "FAIL"
+		case compareResult.Change == stats.ChangeRegression:
+			status = "allowed regression"
+		case queryRecordPair.IsStartedFailing():
+			status = "allowed started failing"
+		case queryRecordPair.IsStoppedFailing():
+			status = "stopped failing"
+		case queryRecordPair.IsFailed():
+			status = "failed"
+		}
+
+		if compareResult.IsFailed() {
+			failedQueries = append(failedQueries, queryRecordPair.LHS.Record.GetName())
+		}
+
+		if queryRecordPair.IsFailed() {
+			fmt.Fprintf(writer, "%s\t-\t-\t-\t-\t-\t%.2f%%\t%s\n",
+				queryRecordPair.LHS.Record.GetName(),
+				compareResult.MaxRegression,
+				status,
+			)
+
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s\t%.2f\t%.2f\t%+.2f%%\t%s\t%.4f\t%.2f%%\t%s\n",
+			queryRecordPair.LHS.Record.GetName(),
+			compareResult.LHSMedian,
+			compareResult.RHSMedian,
+			compareResult.RelativeDiff,
+			confidenceInterval.FormatBounds("%+.2f%%"),
+			compareResult.Comparison.MannWhitneyPValue,
+			compareResult.MaxRegression,
+			status,
+		)
+	}
+
+	_ = writer.Flush() //nolint:errcheck
+
+	suiteSummary := stats.GetSuiteSummary(buildSuiteQueries(queryRecordPairs),
+		compareSettings.SignificanceLevel,
+		compareSettings.MaxRegression,
+		0,
+	)
+
+	fmt.Printf("\nGeometric mean ratio new / old: %.3f %s, improvements: %d, regressions: %d, unchanged: %d\n",
+		suiteSummary.GeometricMeanRatio,
+		suiteSummary.GeometricMeanRatioConfidenceInterval.FormatBounds("%.3f"),
+		suiteSummary.Improvements,
+		suiteSummary.Regressions,
+		suiteSummary.Unchanged,
+	)
+
+	if len(failedQueries) > 0 {
+		logger.Log.Errorf("Queries %v regressed beyond thresholds or started failing", failedQueries)
+		os.Exit(1)
+	}
+}
+
+func buildCompareSettings(cmd *cobra.Command) config.CompareSettings {
+	configuration := config.CreateDefaultConfig()
+
+	if configPath != "" {
+		parsedConfiguration, err := config.ParseConfigFileYaml(configPath)
+		if err != nil {
+			logger.Log.Errorf("Failed to parse config file: %v", err)
+			os.Exit(1)
+		}
+
+		configuration = parsedConfiguration
+	}
+
+	compareSettings := configuration.Compare
+
+	if compareSettings.SignificanceLevel == 0 {
+		compareSettings.SignificanceLevel = compareDefaultSignificanceLevel
+	}
+	if compareSettings.MaxRegression == 0 {
+		compareSettings.MaxRegression = compareDefaultMaxRegression
+	}
+
+	// Command line flags override config file settings
+	if cmd.Flags().Changed("significance-level") {
+		compareSettings.SignificanceLevel = significanceLevel
+	}
+	if cmd.Flags().Changed("max-regression") {
+		compareSettings.MaxRegression = maxRegression
+	}
+
+	compareSettings.AllowedQueries = append(compareSettings.AllowedQueries, allowedQueries...)
+
+	return compareSettings
+}
+
+func compareQueryRecords(queryRecordPairs []QueryRecordPairWithStats,
+	compareSettings config.CompareSettings,
+) []CompareQueryResult {
+	compareResults := []CompareQueryResult{}
+
+	for _, queryRecordPair := range queryRecordPairs {
+		queryName := queryRecordPair.LHS.Record.GetName()
+
+		maxRegression := compareSettings.MaxRegression
+		if queryMaxRegression, ok := compareSettings.QueryMaxRegression[queryName]; ok {
+			maxRegression = queryMaxRegression
+		}
+
+		allowed := slices.Contains(compareSettings.AllowedQueries, queryName)
+
+		if queryRecordPair.IsFailed() {
+			compareResults = append(compareResults, CompareQueryResult{
+				QueryRecordPair: queryRecordPair,
+				MaxRegression:   maxRegression,
+				Allowed:         allowed,
+			})
+
+			continue
+		}
+
+		lhs, rhs := queryRecordPair.LHS, queryRecordPair.RHS
+
+		// Drivers that do not report server durations are compared by client durations, same as in adaptive measure
+		durationComparison := compareQueryDurations(lhs.Stats,
+			rhs.Stats,
+			lhs.Record.ExecutionTimes,
+			rhs.Record.ExecutionTimes,
+		)
+
+		compareResults = append(compareResults, CompareQueryResult{
+			QueryRecordPair: queryRecordPair,
+			LHSMedian:       durationComparison.LHSMedian,
+			RHSMedian:       durationComparison.RHSMedian,
+			Comparison:      durationComparison.Comparison,
+			RelativeDiff:    durationComparison.RelativeDiff,
+			MaxRegression:   maxRegression,
+			Allowed:         allowed,
+			Change: stats.ClassifyChange(durationComparison.RelativeDiff,
+				durationComparison.Comparison,
+				compareSettings.SignificanceLevel,
+				maxRegression,
+			),
+		})
+	}
+
+	return compareResults
+}
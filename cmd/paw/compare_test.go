@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/kitaisreal/paw/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func getCompareQueryRecord(queryNumber int,
+	id string,
+	status string,
+	serverMilliseconds int,
+	clientMilliseconds int,
+	runs int,
+) QueryRecordWithStats {
+	executionTimes := []driver.ExecutionTime{}
+	for i := range runs {
+		executionTimes = append(executionTimes, driver.ExecutionTime{
+			ServerDuration: time.Millisecond * time.Duration(serverMilliseconds) * time.Duration(10+i) / 10,
+			ClientDuration: time.Millisecond * time.Duration(clientMilliseconds) * time.Duration(10+i) / 10,
+		})
+	}
+
+	record := QueryRecord{QueryNumber: queryNumber, ID: id, Status: status, ExecutionTimes: executionTimes}
+	return QueryRecordWithStats{Record: record, Stats: stats.GetStats(executionTimes)}
+}
+
+func TestCompareQueryRecords(t *testing.T) {
+	queryRecordPairs := []QueryRecordPairWithStats{
+		// Regression
+		{LHS: getCompareQueryRecord(0, "", "", 10, 20, 10), RHS: getCompareQueryRecord(0, "", "", 20, 30, 10)},
+		// Regression below query max regression
+		{LHS: getCompareQueryRecord(1, "", "", 10, 20, 10), RHS: getCompareQueryRecord(1, "", "", 12, 22, 10)},
+		// Allowed regression
+		{
+			LHS: getCompareQueryRecord(2, "allowed", "", 10, 20, 10),
+			RHS: getCompareQueryRecord(2, "allowed", "", 20, 30, 10),
+		},
+		// Driver does not report server durations, client durations are compared
+		{LHS: getCompareQueryRecord(3, "", "", 0, 10, 10), RHS: getCompareQueryRecord(3, "", "", 0, 20, 10)},
+		// Stopped failing, partial LHS execution times are faster
+		{LHS: getCompareQueryRecord(4, "", "failed", 1, 1, 3), RHS: getCompareQueryRecord(4, "", "", 10, 20, 10)},
+		// Failed on both sides
+		{LHS: getCompareQueryRecord(5, "", "failed", 0, 0, 0), RHS: getCompareQueryRecord(5, "", "timeout", 0, 0, 0)},
+		// Started failing
+		{LHS: getCompareQueryRecord(6, "", "", 10, 20, 10), RHS: getCompareQueryRecord(6, "", "failed", 1, 1, 3)},
+	}
+
+	compareResults := compareQueryRecords(queryRecordPairs, config.CompareSettings{
+		SignificanceLevel:  0.05,
+		MaxRegression:      5,
+		QueryMaxRegression: map[string]float64{"1": 50},
+		AllowedQueries:     []string{"allowed"},
+	})
+	require.Len(t, compareResults, len(queryRecordPairs))
+
+	require.Equal(t, stats.ChangeRegression, compareResults[0].Change)
+	require.InDelta(t, 100, compareResults[0].RelativeDiff, 1e-9)
+	require.True(t, compareResults[0].IsFailed())
+
+	require.Equal(t, stats.ChangeUnchanged, compareResults[1].Change)
+	require.InDelta(t, 50.0, compareResults[1].MaxRegression, 1e-9)
+	require.False(t, compareResults[1].IsFailed())
+
+	require.Equal(t, stats.ChangeRegression, compareResults[2].Change)
+	require.True(t, compareResults[2].Allowed)
+	require.False(t, compareResults[2].IsFailed())
+
+	require.Equal(t, stats.ChangeRegression, compareResults[3].Change)
+	require.InDelta(t, 14.5, compareResults[3].LHSMedian, 1e-9)
+	require.InDelta(t, 29.0, compareResults[3].RHSMedian, 1e-9)
+
+	// Failed queries are not compared
+	for _, compareResult := range compareResults[4:] {
+		require.Empty(t, compareResult.Change)
+		require.Zero(t, compareResult.RelativeDiff)
+	}
+
+	require.False(t, compareResults[4].IsFailed())
+	require.False(t, compareResults[5].IsFailed())
+	require.True(t, compareResults[6].IsFailed())
+}
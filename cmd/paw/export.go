@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/kitaisreal/paw/internal/collector"
+	"github.com/kitaisreal/paw/internal/logger"
+)
+
+// ViewCollectorFile is collector file that is referenced by query details page.
+type ViewCollectorFile struct {
+	Folder          string
+	QueryFolderName string
+	CollectorName   string
+	FileName        string
+}
+
+// Pages link to each other, to static files and to collector files using links functions, that return server URLs or
+// relative paths in exported directory if view is exported.
+var linkFuncMap = template.FuncMap{
+	"getQueryDetailsLink": func(queryNumber int) string {
+		if exportPath != "" {
+			return getExportQueryDetailsFileName(queryNumber)
+		}
+
+		return fmt.Sprintf("/query/%d", queryNumber)
+	},
+	"getStaticLink": func(fileName string) string {
+		if exportPath != "" {
+			return path.Join("static", fileName)
+		}
+
+		return "/static/" + fileName
+	},
+	"getCollectorFileLink": func(folder string, queryFolderName string, collectorName string, fileName string) string {
+		if exportPath != "" {
+			return path.Join("files", folder, queryFolderName, collectorName, fileName)
+		}
+
+		queryParams := url.Values{}
+		queryParams.Set("folder", folder)
+		queryParams.Set("query", queryFolderName)
+		queryParams.Set("collector", collectorName)
+		queryParams.Set("file", fileName)
+
+		return "/file/?" + queryParams.Encode()
+	},
+}
+
+func getExportQueryDetailsFileName(queryNumber int) string {
+	return fmt.Sprintf("query_%d.html", queryNumber)
+}
+
+func getCollectorFiles(folder string, record QueryRecordWithStats) []ViewCollectorFile {
+	collectorFiles := []ViewCollectorFile{}
+
+	for _, collectorResult := range record.Record.CollectorResults {
+		for _, file := range collectorResult.Files {
+			if file.Type != collector.FileTypeFlamegraph {
+				continue
+			}
+
+			collectorFiles = append(collectorFiles, ViewCollectorFile{
+				Folder:          folder,
+				QueryFolderName: record.Record.GetFolderName(),
+				CollectorName:   collectorResult.Name,
+				FileName:        file.Name,
+			})
+		}
+	}
+
+	return collectorFiles
+}
+
+func getCollectorFilePath(folder string, queryFolderName string, collectorName string, fileName string) string {
+	return filepath.Join(folder, queryFolderName, collectorName, fileName)
+}
+
+// exportView writes index page, query details pages, static files and collector files into exportFolder, so view
+// can be opened or hosted without running server.
+func exportView(viewHTMLPages ViewHTMLPages, exportFolder string, folders []string) error {
+	err := os.MkdirAll(exportFolder, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating export directory %s: %w", exportFolder, err)
+	}
+
+	err = os.WriteFile(filepath.Join(exportFolder, "index.html"), []byte(viewHTMLPages.IndexHTML), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing index page: %w", err)
+	}
+
+	for queryNumber, queryDetailsHTML := range viewHTMLPages.QueryNumberToQueryDetailsHTML {
+		queryDetailsPath := filepath.Join(exportFolder, getExportQueryDetailsFileName(queryNumber))
+
+		err = os.WriteFile(queryDetailsPath, []byte(queryDetailsHTML), 0644)
+		if err != nil {
+			return fmt.Errorf("error writing query %d details page: %w", queryNumber, err)
+		}
+	}
+
+	err = fs.WalkDir(staticFS, "static", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		exportFilePath := filepath.Join(exportFolder, filepath.FromSlash(filePath))
+		if entry.IsDir() {
+			return os.MkdirAll(exportFilePath, 0755)
+		}
+
+		content, err := staticFS.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(exportFilePath, content, 0644)
+	})
+	if err != nil {
+		return fmt.Errorf("error exporting static files: %w", err)
+	}
+
+	for _, collectorFile := range viewHTMLPages.CollectorFiles {
+		folder, ok := getViewFolder(folders, collectorFile.Folder)
+		if !ok {
+			return fmt.Errorf("invalid collector file folder %s", collectorFile.Folder)
+		}
+
+		sourcePath := getCollectorFilePath(folder,
+			collectorFile.QueryFolderName,
+			collectorFile.CollectorName,
+			collectorFile.FileName,
+		)
+		exportFilePath := getCollectorFilePath(filepath.Join(exportFolder, "files", collectorFile.Folder),
+			collectorFile.QueryFolderName,
+			collectorFile.CollectorName,
+			collectorFile.FileName,
+		)
+
+		err = os.MkdirAll(filepath.Dir(exportFilePath), 0755)
+		if err != nil {
+			return fmt.Errorf("error creating directory for collector file %s: %w", exportFilePath, err)
+		}
+
+		// Missing collector file is also not found by server, so page is exported without it
+		err = copyFile(sourcePath, exportFilePath)
+		if err != nil {
+			logger.Log.Warnf("Failed to export collector file %s: %v", sourcePath, err)
+		}
+	}
+
+	return nil
+}
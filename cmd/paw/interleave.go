@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/kitaisreal/paw/internal/logger"
+)
+
+const (
+	// Each measure run of first profile is followed by measure run of second profile
+	interleaveOrderAlternate = "alternate"
+	// Order of profiles is chosen randomly for each pair of measure runs
+	interleaveOrderRandom = "random"
+)
+
+// recordInterleavedQuery records query using each driver, measure runs of drivers are interleaved, so machine drift
+// affects all drivers equally and i-th measure runs of drivers form paired samples. If query fails using one of
+// drivers, its record is marked as failed and measure runs are stopped for all drivers.
+func recordInterleavedQuery(ctx context.Context,
+	drivers []driver.Driver,
+	profilesLoadDrivers [][]driver.Driver,
+	collectors []CollectorWithName,
+	settings config.Settings,
+	queryNumber int,
+	query string,
+	outputPaths []string,
+) []QueryRecord {
+	queryRecords := make([]QueryRecord, len(drivers))
+	failed := false
+
+	for i, driver := range drivers {
+		queryRecord, err := recordQueryWarmup(ctx, driver, settings, queryNumber, query)
+		if err != nil {
+			queryRecord.setFailed(err)
+			failed = true
+		}
+
+		queryRecords[i] = queryRecord
+		queryRecords[i].Interleaved = true
+	}
+
+	measureRuns, adaptive := getMeasureRuns(settings)
+
+	logger.Log.Debugf("Running %v query '%v' interleaved measure runs %v adaptive %v order %v",
+		queryNumber,
+		query,
+		measureRuns,
+		adaptive,
+		interleaveOrder,
+	)
+
+	measureStartTime := time.Now()
+	driverIndexes := make([]int, len(drivers))
+
+	for run := uint64(0); run < measureRuns && !failed; run++ {
+		for i := range driverIndexes {
+			driverIndexes[i] = i
+		}
+
+		if interleaveOrder == interleaveOrderRandom {
+			rand.Shuffle(len(driverIndexes), func(i, j int) {
+				driverIndexes[i], driverIndexes[j] = driverIndexes[j], driverIndexes[i]
+			})
+		}
+
+		for _, driverIndex := range driverIndexes {
+			err := recordMeasureRun(ctx, drivers[driverIndex], settings, &queryRecords[driverIndex], run)
+			if err != nil {
+				queryRecords[driverIndex].setFailed(err)
+				failed = true
+
+				break
+			}
+		}
+
+		if failed || !adaptive {
+			continue
+		}
+
+		// Measure runs are stopped only if all drivers can stop, so samples stay paired
+		stopReasons := make([]string, len(drivers))
+		for i, queryRecord := range queryRecords {
+			stopReasons[i] = getAdaptiveStopReason(queryRecord.ExecutionTimes, settings.Adaptive, time.Since(measureStartTime))
+			if stopReasons[i] == "" {
+				break
+			}
+		}
+
+		if stopReasons[len(stopReasons)-1] == "" {
+			continue
+		}
+
+		for i := range queryRecords {
+			queryRecords[i].MeasureRuns = uint64(len(queryRecords[i].ExecutionTimes))
+			queryRecords[i].MeasureStopReason = stopReasons[i]
+		}
+
+		break
+	}
+
+	logger.Log.Debugf("Finished running %v query '%v' interleaved measure runs %v",
+		queryNumber,
+		query,
+		len(queryRecords[0].ExecutionTimes),
+	)
+
+	for i, driver := range drivers {
+		if queryRecords[i].IsFailed() {
+			continue
+		}
+
+		err := recordQueryCollectors(ctx,
+			driver,
+			profilesLoadDrivers[i],
+			collectors,
+			settings,
+			&queryRecords[i],
+			outputPaths[i],
+		)
+		if err != nil {
+			queryRecords[i].setFailed(err)
+		}
+	}
+
+	return queryRecords
+}
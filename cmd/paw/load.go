@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/kitaisreal/paw/internal/logger"
+)
+
+const (
+	loadDefaultDuration = 10 * time.Second
+	// Worker waits before next run after query error, and stops after max consecutive errors, so failing query
+	// does not spin worker against engine for the whole load phase
+	loadErrorBackoff         = 100 * time.Millisecond
+	loadMaxConsecutiveErrors = 10
+)
+
+type LoadRecord struct {
+	Concurrency    int                    `json:"concurrency"`
+	TargetQPS      float64                `json:"target_qps"`
+	Duration       time.Duration          `json:"duration"`
+	ErrorCount     uint64                 `json:"error_count"`
+	LastError      string                 `json:"last_error,omitempty"`
+	StoppedWorkers int                    `json:"stopped_workers,omitempty"`
+	ExecutionTimes []driver.ExecutionTime `json:"execution_times"`
+}
+
+// recordLoad runs query concurrently using one worker per driver until load duration elapses or load runs are
+// executed. If target QPS is specified, query starts are paced across all workers. duringLoad is called while
+// workers are running, and load phase is not finished until it returns, so collectors can observe the load.
+// Worker that gets query error waits before next run, and stops after max consecutive errors.
+func recordLoad(ctx context.Context,
+	drivers []driver.Driver,
+	loadSettings config.LoadSettings,
+	queryNumber int,
+	query string,
+	duringLoad func(),
+) *LoadRecord {
+	loadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	loadDuration := time.Duration(loadSettings.DurationSeconds) * time.Second
+	if loadDuration == 0 && loadSettings.Runs == 0 {
+		loadDuration = loadDefaultDuration
+	}
+
+	logger.Log.Debugf("Running %v query '%v' load concurrency %v target QPS %v duration %v runs %v",
+		queryNumber,
+		query,
+		loadSettings.Concurrency,
+		loadSettings.QPS,
+		loadDuration,
+		loadSettings.Runs,
+	)
+
+	loadRecord := &LoadRecord{
+		Concurrency:    len(drivers),
+		TargetQPS:      loadSettings.QPS,
+		ExecutionTimes: []driver.ExecutionTime{},
+	}
+
+	var tickChan <-chan time.Time
+	if loadSettings.QPS > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / loadSettings.QPS))
+		defer ticker.Stop()
+
+		tickChan = ticker.C
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var startedRuns atomic.Uint64
+
+	loadStartTime := time.Now()
+
+	for _, drv := range drivers {
+		wg.Add(1)
+
+		go func(drv driver.Driver) {
+			defer wg.Done()
+
+			consecutiveErrors := 0
+
+			for {
+				if tickChan != nil {
+					select {
+					case <-loadCtx.Done():
+						return
+					case <-tickChan:
+					}
+				} else if loadCtx.Err() != nil {
+					return
+				}
+
+				if loadSettings.Runs > 0 && startedRuns.Add(1) > loadSettings.Runs {
+					return
+				}
+
+				executionTime, err := drv.Run(loadCtx, query)
+
+				if err == nil {
+					consecutiveErrors = 0
+
+					mu.Lock()
+					loadRecord.ExecutionTimes = append(loadRecord.ExecutionTimes, executionTime)
+					mu.Unlock()
+
+					continue
+				}
+
+				// Queries interrupted by the end of load phase are not counted as errors
+				if loadCtx.Err() != nil {
+					return
+				}
+
+				consecutiveErrors++
+
+				mu.Lock()
+				loadRecord.ErrorCount++
+				loadRecord.LastError = err.Error()
+				if consecutiveErrors >= loadMaxConsecutiveErrors {
+					loadRecord.StoppedWorkers++
+				}
+				mu.Unlock()
+
+				if consecutiveErrors >= loadMaxConsecutiveErrors {
+					logger.Log.Warnf("Query %v load worker stopped after %v consecutive errors, last error: %v",
+						queryNumber,
+						consecutiveErrors,
+						err,
+					)
+
+					return
+				}
+
+				backoffTimer := time.NewTimer(loadErrorBackoff)
+				select {
+				case <-loadCtx.Done():
+					backoffTimer.Stop()
+					return
+				case <-backoffTimer.C:
+				}
+			}
+		}(drv)
+	}
+
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	duringLoad()
+
+	if loadDuration > 0 {
+		timer := time.NewTimer(time.Until(loadStartTime.Add(loadDuration)))
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-workersDone:
+		}
+	} else {
+		<-workersDone
+	}
+
+	loadRecord.Duration = time.Since(loadStartTime)
+
+	cancel()
+	<-workersDone
+
+	logger.Log.Debugf("Finished running %v query '%v' load runs %v errors %v stopped workers %v duration %v",
+		queryNumber,
+		query,
+		len(loadRecord.ExecutionTimes),
+		loadRecord.ErrorCount,
+		loadRecord.StoppedWorkers,
+		loadRecord.Duration,
+	)
+
+	return loadRecord
+}
@@ -41,21 +41,48 @@ var (
 	}
 
 	viewCmd = &cobra.Command{
-		Use:              "view [folder]",
-		Short:            "View performance test results from a specified folder or difference between two folders",
-		Long:             "View performance test results from a specified folder or difference between two folders",
+		Use:              "view [folder]...",
+		Short:            "View performance test results from a specified folder or difference between folders",
+		Long:             "View performance test results from a specified folder or difference between folders",
 		PersistentPreRun: prerunEnableDebugLogger,
 		Run:              View,
 	}
+
+	compareCmd = &cobra.Command{
+		Use:              "compare [lhs_folder] [rhs_folder]",
+		Short:            "Compare performance test results from two folders and fail on regressions",
+		Long:             "Compare performance test results from two folders and fail on regressions",
+		PersistentPreRun: prerunEnableDebugLogger,
+		Run:              Compare,
+	}
+
+	reportCmd = &cobra.Command{
+		Use:              "report [folder]",
+		Short:            "Report performance test results from a specified folder or difference between two folders",
+		Long:             "Report performance test results from a folder or difference of two folders in Markdown or JSON",
+		PersistentPreRun: prerunEnableDebugLogger,
+		Run:              Report,
+	}
 )
 
 var (
-	configPath string
-	profile    string
-	outputPath string
-	queryIndex int
-	port       int
-	debug      bool
+	configPath    string
+	profiles      []string
+	outputPath    string
+	querySelector string
+	port          int
+	debug         bool
+
+	significanceLevel float64
+	minRelativeDiff   float64
+	maxRegression     float64
+	allowedQueries    []string
+	reportFormat      string
+	exportPath        string
+	baselineIndex     int
+	interleaveOrder   string
+	resume            bool
+	keepGoing         bool
 )
 
 func prerunEnableDebugLogger(_ *cobra.Command, _ []string) {
@@ -74,8 +101,37 @@ func init() {
 		"",
 		"config file for recording",
 	)
-	recordCmd.Flags().StringVarP(&profile, "profile", "p", "clickhouse", "profile for recording (default is clickhouse)")
-	recordCmd.Flags().IntVarP(&queryIndex, "query", "q", -1, "query index for recording (default is all queries)")
+	recordCmd.Flags().StringSliceVarP(&profiles,
+		"profile",
+		"p",
+		[]string{"clickhouse"},
+		"profiles for recording, can be repeated to record each profile into own subfolder (default is clickhouse)",
+	)
+	recordCmd.Flags().StringVarP(&interleaveOrder,
+		"interleave",
+		"",
+		"",
+		"interleave measure runs of two profiles in alternate or random order",
+	)
+	recordCmd.Flags().Lookup("interleave").NoOptDefVal = interleaveOrderAlternate
+	recordCmd.Flags().BoolVarP(&resume,
+		"resume",
+		"",
+		false,
+		"resume recording into existing output folder, only queries without complete records are recorded",
+	)
+	recordCmd.Flags().BoolVarP(&keepGoing,
+		"keep-going",
+		"",
+		false,
+		"keep recording next queries if query or collector fails, failed query record contains error",
+	)
+	recordCmd.Flags().StringVarP(&querySelector,
+		"query",
+		"q",
+		"",
+		"query index or id for recording (default is all queries)",
+	)
 	recordCmd.Flags().StringVarP(&outputPath, "output", "o", "", "output path for recording (default is test name)")
 	recordCmd.Flags().BoolVarP(&debug, "debug", "", false, "enable debug mode")
 	recordCmd.Args = cobra.ExactArgs(1)
@@ -83,7 +139,77 @@ func init() {
 	rootCmd.AddCommand(viewCmd)
 	viewCmd.Flags().IntVarP(&port, "port", "p", 2323, "optional port for viewing (default is 2323)")
 	viewCmd.Flags().BoolVarP(&debug, "debug", "", false, "enable debug mode")
-	viewCmd.Args = cobra.MaximumNArgs(2)
+	viewCmd.Flags().Float64VarP(&significanceLevel,
+		"significance-level",
+		"",
+		0.05,
+		"p-value below which difference is significant (default is 0.05)",
+	)
+	viewCmd.Flags().Float64VarP(&minRelativeDiff,
+		"min-relative-diff",
+		"",
+		2,
+		"minimum median relative difference in percents to highlight significant difference (default is 2)",
+	)
+	viewCmd.Flags().StringVarP(&exportPath,
+		"export",
+		"",
+		"",
+		"export view into static HTML directory instead of running server",
+	)
+	viewCmd.Flags().IntVarP(&baselineIndex,
+		"baseline",
+		"",
+		0,
+		"index of baseline folder if more than two folders are viewed (default is 0)",
+	)
+	viewCmd.Args = cobra.MinimumNArgs(1)
+
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().StringVarP(&configPath, "config", "c", "", "config file with compare settings")
+	compareCmd.Flags().Float64VarP(&significanceLevel,
+		"significance-level",
+		"",
+		compareDefaultSignificanceLevel,
+		"p-value below which regression is significant (default is 0.05)",
+	)
+	compareCmd.Flags().Float64VarP(&maxRegression,
+		"max-regression",
+		"",
+		compareDefaultMaxRegression,
+		"maximum allowed median relative regression in percents (default is 5)",
+	)
+	compareCmd.Flags().StringSliceVarP(&allowedQueries,
+		"allow",
+		"",
+		nil,
+		"ids of queries, or numbers of queries without id, that are allowed to regress",
+	)
+	compareCmd.Flags().BoolVarP(&debug, "debug", "", false, "enable debug mode")
+	compareCmd.Args = cobra.ExactArgs(2)
+
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVarP(&reportFormat,
+		"format",
+		"f",
+		reportFormatMarkdown,
+		"report format, markdown or json (default is markdown)",
+	)
+	reportCmd.Flags().StringVarP(&outputPath, "output", "o", "", "output file for report (default is stdout)")
+	reportCmd.Flags().Float64VarP(&significanceLevel,
+		"significance-level",
+		"",
+		0.05,
+		"p-value below which difference is significant (default is 0.05)",
+	)
+	reportCmd.Flags().Float64VarP(&minRelativeDiff,
+		"min-relative-diff",
+		"",
+		2,
+		"minimum median relative difference in percents to report significant difference (default is 2)",
+	)
+	reportCmd.Flags().BoolVarP(&debug, "debug", "", false, "enable debug mode")
+	reportCmd.Args = cobra.RangeArgs(1, 2)
 }
 
 func main() {
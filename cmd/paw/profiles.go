@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kitaisreal/paw/internal/logger"
+)
+
+// profilesFile is written into output folder if multiple profiles are recorded, it contains profiles in recording
+// order, so view compares profiles subfolders with first profile as baseline.
+const profilesFile = "profiles.json"
+
+func writeProfilesFile(outputPath string, profiles []string) {
+	profilesFilePath := filepath.Join(outputPath, profilesFile)
+
+	jsonData, err := json.MarshalIndent(profiles, "", "  ")
+	if err == nil {
+		err = os.WriteFile(profilesFilePath, jsonData, 0644)
+	}
+
+	if err != nil {
+		logger.Log.Errorf("Failed to write profiles file %s: %v", profilesFilePath, err)
+		os.Exit(1)
+	}
+}
+
+// getProfileFolders returns profiles subfolders if folder contains multiple recorded profiles, otherwise nil.
+func getProfileFolders(folder string) ([]string, error) {
+	profiles, err := readProfilesFile(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	profileFolders := make([]string, len(profiles))
+	for i, profile := range profiles {
+		profileFolders[i] = filepath.Join(folder, profile)
+	}
+
+	return profileFolders, nil
+}
+
+// readProfilesFile returns profiles recorded into folder, or nil if folder does not contain profiles file.
+func readProfilesFile(folder string) ([]string, error) {
+	profilesFilePath := filepath.Join(folder, profilesFile)
+
+	content, err := os.ReadFile(profilesFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading profiles file %s: %w", profilesFilePath, err)
+	}
+
+	var profiles []string
+
+	err = json.Unmarshal(content, &profiles)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling profiles file %s: %w", profilesFilePath, err)
+	}
+
+	return profiles, nil
+}
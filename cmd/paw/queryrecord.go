@@ -2,24 +2,104 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/kitaisreal/paw/internal/collector"
 	"github.com/kitaisreal/paw/internal/driver"
 	"github.com/kitaisreal/paw/internal/stats"
 )
 
+const (
+	queryFolderPrefix = "query_"
+	queryRecordFile   = "query_record.json"
+)
+
+// Query record status is empty if query is recorded successfully.
+const (
+	queryRecordStatusFailed = "failed"
+	// Query run exceeded query timeout
+	queryRecordStatusTimeout = "timeout"
+	// Recording was interrupted by signal or test timeout while query was recorded
+	queryRecordStatusCancelled = "cancelled"
+)
+
 type QueryRecord struct {
-	QueryNumber      int                    `json:"query_number"`
-	Query            string                 `json:"query"`
-	ExecutionTimes   []driver.ExecutionTime `json:"execution_times"`
-	CollectorResults []collector.Result     `json:"collector_results"`
+	QueryNumber int    `json:"query_number"`
+	Query       string `json:"query"`
+	// ID, Tags and Description are specified in test file, query with ID is stored in query_<ID> folder and is matched
+	// with queries of other test results by ID
+	ID          string   `json:"id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
+	// Parameters contains parameters values that were substituted into query template
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Status and Error are set if query run or collector failed, timed out or was cancelled, record contains execution
+	// times that were recorded before failure
+	Status         string                 `json:"status,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	ResultHash     string                 `json:"result_hash,omitempty"`
+	ResultRows     uint64                 `json:"result_rows,omitempty"`
+	ExecutionTimes []driver.ExecutionTime `json:"execution_times"`
+	// MeasureRuns and MeasureStopReason are set in adaptive mode, they contain number of measure runs that were
+	// executed and reason why measure runs were stopped
+	MeasureRuns       uint64 `json:"measure_runs,omitempty"`
+	MeasureStopReason string `json:"measure_stop_reason,omitempty"`
+	// Interleaved is set if measure runs were interleaved with measure runs of other profile, so i-th execution times
+	// of both profiles records are paired
+	Interleaved bool `json:"interleaved,omitempty"`
+	// WarmupExecutionTimes are executed before measure runs and are not included in stats
+	WarmupExecutionTimes []driver.ExecutionTime `json:"warmup_execution_times,omitempty"`
+	// ColdExecutionTimes are executed after dropping caches and are stored separately from hot measure runs
+	ColdExecutionTimes []driver.ExecutionTime `json:"cold_execution_times,omitempty"`
+	CollectorResults   []collector.Result     `json:"collector_results"`
+	Load               *LoadRecord            `json:"load,omitempty"`
+}
+
+// GetName returns query id if it is specified, otherwise query number.
+func (r QueryRecord) GetName() string {
+	if r.ID != "" {
+		return r.ID
+	}
+
+	return strconv.Itoa(r.QueryNumber)
+}
+
+// GetFolderName returns name of query folder in test result folder, that is also used to match query records of
+// different test results.
+func (r QueryRecord) GetFolderName() string {
+	return getQueryFolderName(r.QueryNumber, r.ID)
+}
+
+func getQueryFolderName(queryNumber int, id string) string {
+	if id != "" {
+		return queryFolderPrefix + id
+	}
+
+	return fmt.Sprintf("%s%d", queryFolderPrefix, queryNumber)
+}
+
+func (r QueryRecord) IsFailed() bool {
+	return r.Status != ""
+}
+
+func (r *QueryRecord) setFailed(err error) {
+	r.Status = queryRecordStatusFailed
+	if errors.Is(err, errQueryTimeout) {
+		r.Status = queryRecordStatusTimeout
+	}
+
+	r.Error = err.Error()
 }
 
 type QueryRecordWithStats struct {
-	Record QueryRecord
-	Stats  stats.Stats
+	Record      QueryRecord
+	Stats       stats.Stats
+	WarmupStats stats.Stats
+	ColdStats   stats.Stats
+	LoadStats   stats.LoadStats
 }
 
 type QueryRecordPair struct {
@@ -32,6 +112,38 @@ type QueryRecordPairWithStats struct {
 	RHS QueryRecordWithStats
 }
 
+// IsInterleaved returns true if measure runs of both records were interleaved, so their execution times are paired.
+func (p QueryRecordPairWithStats) IsInterleaved() bool {
+	return p.LHS.Record.Interleaved && p.RHS.Record.Interleaved
+}
+
+// IsStartedFailing returns true if query is recorded successfully in LHS and failed in RHS.
+func (p QueryRecordPairWithStats) IsStartedFailing() bool {
+	return !p.LHS.Record.IsFailed() && p.RHS.Record.IsFailed()
+}
+
+// IsStoppedFailing returns true if query failed in LHS and is recorded successfully in RHS.
+func (p QueryRecordPairWithStats) IsStoppedFailing() bool {
+	return p.LHS.Record.IsFailed() && !p.RHS.Record.IsFailed()
+}
+
+// IsFailed returns true if query failed in LHS or RHS, execution times of failed query are partial, so they are not
+// compared in suite summary.
+func (p QueryRecordPairWithStats) IsFailed() bool {
+	return p.LHS.Record.IsFailed() || p.RHS.Record.IsFailed()
+}
+
+// IsQueryMismatch returns true if records are matched by query id, but query text differs.
+func (p QueryRecordPairWithStats) IsQueryMismatch() bool {
+	return p.LHS.Record.ID != "" && p.LHS.Record.Query != p.RHS.Record.Query
+}
+
+// IsResultMismatch returns true if both records have result hash and hashes differ.
+func (p QueryRecordPairWithStats) IsResultMismatch() bool {
+	lhsHash, rhsHash := p.LHS.Record.ResultHash, p.RHS.Record.ResultHash
+	return lhsHash != "" && rhsHash != "" && lhsHash != rhsHash
+}
+
 func serializeQueryRecord(filePath string, record QueryRecord) error {
 	jsonData, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kitaisreal/paw/internal/collector"
 	"github.com/kitaisreal/paw/internal/config"
@@ -15,13 +18,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func Record(_ *cobra.Command, args []string) {
+func Record(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
 		logger.Log.Error("No test files specified")
 		os.Exit(1)
 	}
 
-	ctx := context.Background()
 	testFilePath := args[0]
 
 	configuration := config.CreateDefaultConfig()
@@ -38,32 +40,147 @@ func Record(_ *cobra.Command, args []string) {
 		usingConfigMessage = fmt.Sprintf("using config file: %s", configPath)
 	}
 
-	configurationSettings := configuration.Settings
 	test, err := config.ParseTestFileYaml(testFilePath)
 	if err != nil {
 		logger.Log.Errorf("Failed to parse test file %s: %v", testFilePath, err)
 		os.Exit(1)
 	}
 
-	logger.Log.Infof("Recording performance for test file: %v %s, profile: %s, measure runs: %v",
+	// Command line profiles override test file profiles
+	recordProfiles := profiles
+	if !cmd.Flags().Changed("profile") && len(test.Profiles) > 0 {
+		recordProfiles = test.Profiles
+	}
+
+	for i, profile := range recordProfiles {
+		if slices.Contains(recordProfiles[:i], profile) {
+			logger.Log.Errorf("Profile %s is specified multiple times", profile)
+			os.Exit(1)
+		}
+	}
+
+	querySelected := false
+	for index, testQuery := range test.Queries {
+		querySelected = querySelected || isQuerySelected(testQuery, index)
+	}
+
+	if !querySelected {
+		logger.Log.Errorf("Query %s not found in test file %s", querySelector, testFilePath)
+		os.Exit(1)
+	}
+
+	if interleaveOrder != "" && len(recordProfiles) != 2 {
+		logger.Log.Errorf("Interleaved recording requires two profiles, got %d profiles", len(recordProfiles))
+		os.Exit(1)
+	}
+
+	if interleaveOrder != "" && interleaveOrder != interleaveOrderAlternate && interleaveOrder != interleaveOrderRandom {
+		logger.Log.Errorf("Invalid interleave order: %s, expected %s or %s",
+			interleaveOrder,
+			interleaveOrderAlternate,
+			interleaveOrderRandom,
+		)
+		os.Exit(1)
+	}
+
+	logger.Log.Infof("Recording performance for test file: %v %s, profiles: %v, measure runs: %v",
 		testFilePath,
 		usingConfigMessage,
-		profile,
-		configurationSettings.QueryMeasureRuns)
+		recordProfiles,
+		configuration.Settings.QueryMeasureRuns)
 
 	if outputPath == "" {
 		outputPath = test.Name
 	}
 
-	createOutputPath(outputPath)
-	copyConfigurationFiles(configPath, testFilePath, outputPath)
+	prepareOutputPath(outputPath)
 
-	driver := buildDriver(configuration, profile)
 	collectors := buildCollectors(configuration, test)
 	for _, collector := range collectors {
 		defer collector.cleanup()
 	}
 
+	ctx, cancel := buildRecordContext(configuration.Settings)
+	defer cancel()
+
+	if len(recordProfiles) == 1 {
+		prepareConfigurationFiles(configPath, testFilePath, outputPath)
+		err := recordTest(ctx, configuration, test, collectors, recordProfiles, []string{outputPath})
+		if err != nil {
+			stopRecording(collectors, err)
+		}
+
+		return
+	}
+
+	// Each profile is recorded into its own subfolder, view of output folder compares all profiles
+	prepareProfilesFile(outputPath, recordProfiles)
+
+	profileOutputPaths := []string{}
+	for _, profile := range recordProfiles {
+		profileOutputPath := filepath.Join(outputPath, profile)
+		createDirectoryOrExit(profileOutputPath)
+		prepareConfigurationFiles(configPath, testFilePath, profileOutputPath)
+
+		profileOutputPaths = append(profileOutputPaths, profileOutputPath)
+	}
+
+	if interleaveOrder != "" {
+		logger.Log.Infof("Recording profiles: %v interleaved to %v", recordProfiles, profileOutputPaths)
+		err := recordTest(ctx, configuration, test, collectors, recordProfiles, profileOutputPaths)
+		if err != nil {
+			stopRecording(collectors, err)
+		}
+
+		return
+	}
+
+	for i, profile := range recordProfiles {
+		logger.Log.Infof("Recording profile: %s to %s", profile, profileOutputPaths[i])
+		err := recordTest(ctx, configuration, test, collectors, []string{profile}, profileOutputPaths[i:i+1])
+		if err != nil {
+			stopRecording(collectors, err)
+		}
+	}
+}
+
+// stopRecording cleans up collectors and exits after recording is cancelled by signal or test timeout.
+func stopRecording(collectors []CollectorWithName, err error) {
+	for _, collector := range collectors {
+		collector.cleanup()
+	}
+
+	logger.Log.Errorf("Recording stopped: %v, recorded queries are saved and can be resumed using --resume", err)
+	os.Exit(1)
+}
+
+// recordTest records test queries of each profile into output path with same index. If multiple profiles are
+// specified, their measure runs are interleaved. Error is returned if recording is cancelled, records of queries that
+// were recorded before cancellation are saved.
+func recordTest(ctx context.Context,
+	configuration config.Config,
+	test config.Test,
+	collectors []CollectorWithName,
+	profiles []string,
+	outputPaths []string,
+) error {
+	configurationSettings := configuration.Settings
+
+	drivers := []driver.Driver{}
+	profilesLoadDrivers := [][]driver.Driver{}
+
+	for _, profile := range profiles {
+		drivers = append(drivers, buildDriver(configuration, profile))
+
+		// Each load worker uses its own driver, drivers are not safe for concurrent use
+		loadDrivers := []driver.Driver{}
+		for range configurationSettings.Load.Concurrency {
+			loadDrivers = append(loadDrivers, buildDriver(configuration, profile))
+		}
+
+		profilesLoadDrivers = append(profilesLoadDrivers, loadDrivers)
+	}
+
 	logger.Log.Debugf("Recording started")
 
 	const fixedDescriptionWidth = 80
@@ -81,11 +198,17 @@ func Record(_ *cobra.Command, args []string) {
 	progressBar.Describe(description)
 	_ = progressBar.RenderBlank() //nolint:errcheck
 
-	for index, query := range test.Queries {
-		if queryIndex >= 0 && queryIndex != index {
+	for index, testQuery := range test.Queries {
+		if !isQuerySelected(testQuery, index) {
 			continue
 		}
 
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		query := testQuery.Query
+
 		description = fmt.Sprintf("Running query %d: %s", index, query)
 		if len(description) < fixedDescriptionWidth {
 			description += strings.Repeat(" ", fixedDescriptionWidth-len(description))
@@ -96,32 +219,148 @@ func Record(_ *cobra.Command, args []string) {
 		progressBar.Describe(description)
 		_ = progressBar.RenderBlank() //nolint:errcheck
 
-		queryDirName := fmt.Sprintf("%s/query_%d", outputPath, index)
+		queryDirNames := []string{}
+		for _, outputPath := range outputPaths {
+			queryDirNames = append(queryDirNames, filepath.Join(outputPath, getQueryFolderName(index, testQuery.ID)))
+		}
+
+		if resume && isQueryRecorded(queryDirNames) {
+			logger.Log.Debugf("Skipping %v query '%v' that is already recorded", index, query)
+			_ = progressBar.Add(1) //nolint:errcheck
 
-		removeDirectoryOrExit(queryDirName)
-		createDirectoryOrExit(queryDirName)
+			continue
+		}
 
-		queryRecord := recordQuery(ctx,
-			driver,
+		for _, queryDirName := range queryDirNames {
+			removeDirectoryOrExit(queryDirName)
+			createDirectoryOrExit(queryDirName)
+		}
+
+		queryRecords := recordTestQuery(ctx,
+			drivers,
+			profilesLoadDrivers,
 			collectors,
-			configurationSettings.QueryMeasureRuns,
+			testQuery.GetSettings(configurationSettings),
 			index,
 			query,
-			queryDirName,
+			queryDirNames,
 		)
 
-		fileName := fmt.Sprintf("%s/query_record.json", queryDirName)
-		err := serializeQueryRecord(fileName, queryRecord)
-		if err != nil {
-			logger.Log.Errorf("Failed to save %v query '%v' record result to %s: %v", index, query, fileName, err)
-			os.Exit(1)
+		for i := range queryRecords {
+			queryRecords[i].ID = testQuery.ID
+			queryRecords[i].Tags = testQuery.Tags
+			queryRecords[i].Description = testQuery.Description
+			queryRecords[i].Parameters = testQuery.ParameterSet
+		}
+
+		// Records are saved before failures are handled, so failed and cancelled queries keep partial results
+		for i, queryRecord := range queryRecords {
+			fileName := fmt.Sprintf("%s/query_record.json", queryDirNames[i])
+			err := serializeQueryRecord(fileName, queryRecord)
+			if err != nil {
+				logger.Log.Errorf("Failed to save %v query '%v' record result to %s: %v", index, query, fileName, err)
+				os.Exit(1)
+			}
+
+			logger.Log.Debugf("Saved %v query '%v' record result to %s", index, query, fileName)
+		}
+
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		for _, queryRecord := range queryRecords {
+			if queryRecord.IsFailed() {
+				handleFailedQueryRecord(queryRecord)
+			}
 		}
 
-		logger.Log.Debugf("Saved %v query '%v' record result to %s", index, query, fileName)
 		_ = progressBar.Add(1) //nolint:errcheck
 	}
 
 	logger.Log.Debugf("Recording completed")
+
+	return nil
+}
+
+// recordTestQuery records query using each driver, query runs are cancelled if they exceed query timeout. If recording
+// is cancelled while query is recorded, failed query records are marked as cancelled.
+func recordTestQuery(ctx context.Context,
+	drivers []driver.Driver,
+	profilesLoadDrivers [][]driver.Driver,
+	collectors []CollectorWithName,
+	settings config.Settings,
+	queryNumber int,
+	query string,
+	queryDirNames []string,
+) []QueryRecord {
+	queryDrivers := make([]driver.Driver, len(drivers))
+	for i, drv := range drivers {
+		queryDrivers[i] = withQueryTimeout(drv, settings.QueryTimeout)
+	}
+
+	var queryRecords []QueryRecord
+	if len(queryDrivers) == 1 {
+		queryRecord, err := recordQuery(ctx,
+			queryDrivers[0],
+			profilesLoadDrivers[0],
+			collectors,
+			settings,
+			queryNumber,
+			query,
+			queryDirNames[0],
+		)
+		if err != nil {
+			queryRecord.setFailed(err)
+		}
+
+		queryRecords = []QueryRecord{queryRecord}
+	} else {
+		queryRecords = recordInterleavedQuery(ctx,
+			queryDrivers,
+			profilesLoadDrivers,
+			collectors,
+			settings,
+			queryNumber,
+			query,
+			queryDirNames,
+		)
+	}
+
+	if ctx.Err() != nil {
+		for i := range queryRecords {
+			if queryRecords[i].IsFailed() {
+				queryRecords[i].Status = queryRecordStatusCancelled
+				queryRecords[i].Error = fmt.Sprintf("%v: %s", context.Cause(ctx), queryRecords[i].Error)
+			}
+		}
+	}
+
+	return queryRecords
+}
+
+// isQuerySelected returns true if query with index is selected for recording by its index or id.
+func isQuerySelected(query config.Query, index int) bool {
+	if querySelector == "" {
+		return true
+	}
+
+	if query.ID != "" && query.ID == querySelector {
+		return true
+	}
+
+	return querySelector == strconv.Itoa(index)
+}
+
+// handleFailedQueryRecord stops recording if query failed, unless recording should keep going, then failed query
+// record is saved and next queries are recorded.
+func handleFailedQueryRecord(queryRecord QueryRecord) {
+	if !keepGoing {
+		logger.Log.Errorf("Failed to record %v query '%v': %s", queryRecord.QueryNumber, queryRecord.Query, queryRecord.Error)
+		os.Exit(1)
+	}
+
+	logger.Log.Warnf("Failed to record %v query '%v': %s", queryRecord.QueryNumber, queryRecord.Query, queryRecord.Error)
 }
 
 func createOutputPath(outputPath string) {
@@ -167,32 +406,177 @@ func copyConfigurationFiles(configPath string, testFilePath string, outputPath s
 	}
 }
 
+// recordQuery returns query record and error of first failed query run or collector, record contains execution times
+// that were recorded before failure.
 func recordQuery(ctx context.Context,
 	driver driver.Driver,
+	loadDrivers []driver.Driver,
 	collectors []CollectorWithName,
-	measureRuns uint64,
+	settings config.Settings,
 	queryNumber int,
 	query string,
 	outputPath string,
-) QueryRecord {
+) (QueryRecord, error) {
+	queryRecord, err := recordQueryWarmup(ctx, driver, settings, queryNumber, query)
+	if err != nil {
+		return queryRecord, err
+	}
+
+	measureRuns, adaptive := getMeasureRuns(settings)
+
+	logger.Log.Debugf("Running %v query '%v' measure runs %v adaptive %v", queryNumber, query, measureRuns, adaptive)
+
+	measureStartTime := time.Now()
+
+	for run := uint64(0); run < measureRuns; run++ {
+		err := recordMeasureRun(ctx, driver, settings, &queryRecord, run)
+		if err != nil {
+			return queryRecord, err
+		}
+
+		if adaptive {
+			stopReason := getAdaptiveStopReason(queryRecord.ExecutionTimes, settings.Adaptive, time.Since(measureStartTime))
+			if stopReason != "" {
+				queryRecord.MeasureRuns = uint64(len(queryRecord.ExecutionTimes))
+				queryRecord.MeasureStopReason = stopReason
+				break
+			}
+		}
+	}
+
+	logger.Log.Debugf("Finished running %v query '%v' measure runs %v stop reason '%v'",
+		queryNumber,
+		query,
+		len(queryRecord.ExecutionTimes),
+		queryRecord.MeasureStopReason,
+	)
+
+	err = recordQueryCollectors(ctx, driver, loadDrivers, collectors, settings, &queryRecord, outputPath)
+
+	return queryRecord, err
+}
+
+// recordQueryWarmup returns query record with cold and warmup runs, that are executed before measure runs.
+func recordQueryWarmup(ctx context.Context,
+	driver driver.Driver,
+	settings config.Settings,
+	queryNumber int,
+	query string,
+) (QueryRecord, error) {
 	queryRecord := QueryRecord{
 		QueryNumber: queryNumber,
 		Query:       query,
 	}
 
-	logger.Log.Debugf("Running %v query '%v' measure runs %v", queryNumber, query, measureRuns)
+	if settings.Cold.Runs > 0 {
+		coldExecutionTimes, err := recordColdRuns(ctx, driver, settings.Cold, queryNumber, query)
+		queryRecord.ColdExecutionTimes = coldExecutionTimes
 
-	for run := uint64(0); run < measureRuns; run++ {
+		if err != nil {
+			return queryRecord, fmt.Errorf("cold run error: %w", err)
+		}
+	}
+
+	warmupRuns := settings.QueryWarmupRuns
+
+	logger.Log.Debugf("Running %v query '%v' warmup runs %v", queryNumber, query, warmupRuns)
+
+	for run := uint64(0); run < warmupRuns; run++ {
 		executionTime, err := driver.Run(ctx, query)
 		if err != nil {
-			logger.Log.Errorf("Failed to run %v query '%v': %v", queryNumber, query, err)
-			os.Exit(1)
+			return queryRecord, fmt.Errorf("warmup run error: %w", err)
 		}
 
-		queryRecord.ExecutionTimes = append(queryRecord.ExecutionTimes, executionTime)
+		queryRecord.WarmupExecutionTimes = append(queryRecord.WarmupExecutionTimes, executionTime)
+	}
+
+	return queryRecord, nil
+}
+
+// getMeasureRuns returns maximum number of measure runs and whether measure runs can stop earlier in adaptive mode.
+func getMeasureRuns(settings config.Settings) (uint64, bool) {
+	if settings.Adaptive.MaxRuns > 0 {
+		return settings.Adaptive.MaxRuns, true
+	}
+
+	return settings.QueryMeasureRuns, false
+}
+
+func recordMeasureRun(ctx context.Context,
+	driver driver.Driver,
+	settings config.Settings,
+	queryRecord *QueryRecord,
+	run uint64,
+) error {
+	queryNumber, query := queryRecord.QueryNumber, queryRecord.Query
+
+	executionTime, err := driver.Run(ctx, query)
+	if err != nil {
+		return fmt.Errorf("measure run %v error: %w", run, err)
 	}
 
-	logger.Log.Debugf("Finished running %v query '%v' measure runs %v", queryNumber, query, measureRuns)
+	if run == 0 {
+		queryRecord.ResultHash = executionTime.ResultHash
+		queryRecord.ResultRows = executionTime.ResultRows
+	} else if executionTime.ResultHash != queryRecord.ResultHash {
+		logger.Log.Warnf("Query %v '%v' run %v result hash %s rows %v differs from first run result hash %s rows %v",
+			queryNumber,
+			query,
+			run,
+			executionTime.ResultHash,
+			executionTime.ResultRows,
+			queryRecord.ResultHash,
+			queryRecord.ResultRows,
+		)
+
+		if settings.QueryFailOnResultMismatch {
+			return fmt.Errorf("measure run %v result differs from first run result", run)
+		}
+	}
+
+	queryRecord.ExecutionTimes = append(queryRecord.ExecutionTimes, executionTime)
+
+	return nil
+}
+
+// recordQueryCollectors runs load and collectors after measure runs.
+func recordQueryCollectors(ctx context.Context,
+	driver driver.Driver,
+	loadDrivers []driver.Driver,
+	collectors []CollectorWithName,
+	settings config.Settings,
+	queryRecord *QueryRecord,
+	outputPath string,
+) error {
+	queryNumber, query := queryRecord.QueryNumber, queryRecord.Query
+	collectDuringLoad := len(loadDrivers) > 0 && settings.Load.CollectDuringLoad
+
+	var err error
+
+	if len(loadDrivers) > 0 {
+		queryRecord.Load = recordLoad(ctx, loadDrivers, settings.Load, queryNumber, query, func() {
+			if collectDuringLoad {
+				queryRecord.CollectorResults, err = collectQuery(ctx, driver, collectors, queryNumber, query, outputPath)
+			}
+		})
+	}
+
+	if !collectDuringLoad {
+		queryRecord.CollectorResults, err = collectQuery(ctx, driver, collectors, queryNumber, query, outputPath)
+	}
+
+	return err
+}
+
+// collectQuery returns results of collectors that finished before first failed collector.
+func collectQuery(ctx context.Context,
+	driver driver.Driver,
+	collectors []CollectorWithName,
+	queryNumber int,
+	query string,
+	outputPath string,
+) ([]collector.Result, error) {
+	collectorResults := []collector.Result{}
 
 	for _, collectorWithName := range collectors {
 		collector, collectorName := collectorWithName.collector, collectorWithName.name
@@ -200,8 +584,11 @@ func recordQuery(ctx context.Context,
 		collectorDirName := fmt.Sprintf("%s/%s", outputPath, collectorName)
 		err := os.MkdirAll(collectorDirName, 0755)
 		if err != nil {
-			logger.Log.Errorf("Failed to create directory %s for collector %s: %v", collectorDirName, collectorName, err)
-			os.Exit(1)
+			return collectorResults, fmt.Errorf("error creating directory %s for collector %s: %w",
+				collectorDirName,
+				collectorName,
+				err,
+			)
 		}
 
 		logger.Log.Debugf("Collecting using %s collector for %v query '%v' saving to %s",
@@ -212,8 +599,7 @@ func recordQuery(ctx context.Context,
 		)
 		collectorResult, err := collector.Collect(ctx, driver, query, collectorDirName)
 		if err != nil {
-			logger.Log.Errorf("Failed to collect %s: %v", collectorName, err)
-			os.Exit(1)
+			return collectorResults, fmt.Errorf("collector %s error: %w", collectorName, err)
 		}
 
 		logger.Log.Debugf("Collected using %s collector for %v query '%v' finished",
@@ -221,10 +607,10 @@ func recordQuery(ctx context.Context,
 			queryNumber,
 			query,
 		)
-		queryRecord.CollectorResults = append(queryRecord.CollectorResults, collectorResult)
+		collectorResults = append(collectorResults, collectorResult)
 	}
 
-	return queryRecord
+	return collectorResults, nil
 }
 
 func buildDriver(configuration config.Config, profile string) driver.Driver {
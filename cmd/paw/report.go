@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kitaisreal/paw/internal/collector"
+	"github.com/kitaisreal/paw/internal/logger"
+	"github.com/kitaisreal/paw/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+const (
+	reportFormatMarkdown = "markdown"
+	reportFormatJSON     = "json"
+)
+
+// ReportData contains stats of single test folder, or stats and differences of two test folders.
+type ReportData struct {
+	LHSFolder    string              `json:"lhs_folder"`
+	RHSFolder    string              `json:"rhs_folder,omitempty"`
+	SuiteSummary *stats.SuiteSummary `json:"suite_summary,omitempty"`
+	// ResultMismatchQueries contains ids of queries, or numbers of queries without id, whose results differ
+	ResultMismatchQueries []string      `json:"result_mismatch_queries,omitempty"`
+	Queries               []ReportQuery `json:"queries"`
+}
+
+type ReportQuery struct {
+	QueryNumber int                `json:"query_number"`
+	Query       string             `json:"query"`
+	ID          string             `json:"id,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Parameters  map[string]string  `json:"parameters,omitempty"`
+	LHS         ReportQueryRecord  `json:"lhs"`
+	RHS         *ReportQueryRecord `json:"rhs,omitempty"`
+	Diff        *ReportQueryDiff   `json:"diff,omitempty"`
+}
+
+// GetName returns query id if it is specified, otherwise query number, same as QueryRecord.GetName.
+func (q ReportQuery) GetName() string {
+	if q.ID != "" {
+		return q.ID
+	}
+
+	return strconv.Itoa(q.QueryNumber)
+}
+
+type ReportQueryRecord struct {
+	Status            string           `json:"status,omitempty"`
+	Error             string           `json:"error,omitempty"`
+	Runs              int              `json:"runs"`
+	ResultHash        string           `json:"result_hash,omitempty"`
+	ResultRows        uint64           `json:"result_rows,omitempty"`
+	MeasureRuns       uint64           `json:"measure_runs,omitempty"`
+	MeasureStopReason string           `json:"measure_stop_reason,omitempty"`
+	Stats             stats.Stats      `json:"stats"`
+	WarmupStats       *stats.Stats     `json:"warmup_stats,omitempty"`
+	ColdStats         *stats.Stats     `json:"cold_stats,omitempty"`
+	LoadStats         *stats.LoadStats `json:"load_stats,omitempty"`
+	// Flamegraphs contains paths to flamegraph files of all collectors
+	Flamegraphs []string `json:"flamegraphs,omitempty"`
+}
+
+type ReportQueryDiff struct {
+	RelativeMedianServerDurationDiff float64          `json:"relative_median_server_duration_diff"`
+	RelativeMedianClientDurationDiff float64          `json:"relative_median_client_duration_diff"`
+	ServerDurationComparison         stats.Comparison `json:"server_duration_comparison"`
+	ClientDurationComparison         stats.Comparison `json:"client_duration_comparison"`
+	Change                           stats.Change     `json:"change"`
+	// ClientDurationsCompared is true if change is classified by client durations, because server durations are
+	// missing on any side
+	ClientDurationsCompared bool `json:"client_durations_compared,omitempty"`
+	ResultMismatch          bool `json:"result_mismatch"`
+}
+
+func Report(_ *cobra.Command, args []string) {
+	if reportFormat != reportFormatMarkdown && reportFormat != reportFormatJSON {
+		logger.Log.Errorf("Invalid report format: %s, expected %s or %s",
+			reportFormat,
+			reportFormatMarkdown,
+			reportFormatJSON,
+		)
+		os.Exit(1)
+	}
+
+	// Flamegraph paths are relative to report file folder, so links work when report is stored next to results
+	linkFolder := "."
+	if outputPath != "" {
+		linkFolder = filepath.Dir(outputPath)
+	}
+
+	var report ReportData
+	if len(args) == 1 {
+		report = buildSingleReport(buildViewSingleData(convertPathToFolder(args[0])), linkFolder)
+	} else {
+		report = buildDiffReport(buildViewDiffData(convertPathToFolder(args[0]), convertPathToFolder(args[1])),
+			linkFolder,
+		)
+	}
+
+	var reportContent []byte
+	if reportFormat == reportFormatJSON {
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			logger.Log.Errorf("Failed to serialize report: %v", err)
+			os.Exit(1)
+		}
+
+		reportContent = append(jsonData, '\n')
+	} else {
+		reportContent = []byte(renderMarkdownReport(report))
+	}
+
+	if outputPath == "" {
+		_, _ = os.Stdout.Write(reportContent) //nolint:errcheck
+		return
+	}
+
+	err := os.WriteFile(outputPath, reportContent, 0644)
+	if err != nil {
+		logger.Log.Errorf("Failed to write report file %s: %v", outputPath, err)
+		os.Exit(1)
+	}
+}
+
+func buildSingleReport(data ViewSingleData, linkFolder string) ReportData {
+	report := ReportData{LHSFolder: data.FolderName, Queries: []ReportQuery{}}
+
+	for _, record := range data.Records {
+		report.Queries = append(report.Queries, ReportQuery{
+			QueryNumber: record.Record.QueryNumber,
+			Query:       record.Record.Query,
+			ID:          record.Record.ID,
+			Tags:        record.Record.Tags,
+			Description: record.Record.Description,
+			Parameters:  record.Record.Parameters,
+			LHS:         buildReportQueryRecord(record, data.FolderName, linkFolder),
+		})
+	}
+
+	return report
+}
+
+func buildDiffReport(data ViewDiffData, linkFolder string) ReportData {
+	report := ReportData{
+		LHSFolder:             data.LHSFolder,
+		RHSFolder:             data.RHSFolder,
+		SuiteSummary:          &data.SuiteSummary,
+		ResultMismatchQueries: data.ResultMismatchQueries,
+		Queries:               []ReportQuery{},
+	}
+
+	for _, queryRecordPair := range data.QueryRecordPairs {
+		lhs, rhs := queryRecordPair.LHS, queryRecordPair.RHS
+		rhsRecord := buildReportQueryRecord(rhs, data.RHSFolder, linkFolder)
+
+		diff := &ReportQueryDiff{
+			RelativeMedianServerDurationDiff: getRelativeDiff(lhs.Stats.GetMedianServerDurationMilliseconds(),
+				rhs.Stats.GetMedianServerDurationMilliseconds(),
+			),
+			RelativeMedianClientDurationDiff: getRelativeDiff(lhs.Stats.GetMedianClientDurationMilliseconds(),
+				rhs.Stats.GetMedianClientDurationMilliseconds(),
+			),
+			ServerDurationComparison: stats.CompareServerDurations(lhs.Record.ExecutionTimes, rhs.Record.ExecutionTimes),
+			ClientDurationComparison: stats.CompareClientDurations(lhs.Record.ExecutionTimes, rhs.Record.ExecutionTimes),
+			ResultMismatch:           queryRecordPair.IsResultMismatch(),
+		}
+
+		// Drivers that do not report server durations are compared by client durations, same as in compare
+		diff.ClientDurationsCompared = !stats.HasServerDurations(lhs.Record.ExecutionTimes) ||
+			!stats.HasServerDurations(rhs.Record.ExecutionTimes)
+		if diff.ClientDurationsCompared {
+			diff.Change = stats.ClassifyChange(diff.RelativeMedianClientDurationDiff,
+				diff.ClientDurationComparison,
+				significanceLevel,
+				minRelativeDiff,
+			)
+		} else {
+			diff.Change = stats.ClassifyChange(diff.RelativeMedianServerDurationDiff,
+				diff.ServerDurationComparison,
+				significanceLevel,
+				minRelativeDiff,
+			)
+		}
+
+		report.Queries = append(report.Queries, ReportQuery{
+			QueryNumber: lhs.Record.QueryNumber,
+			Query:       lhs.Record.Query,
+			ID:          lhs.Record.ID,
+			Tags:        lhs.Record.Tags,
+			Description: lhs.Record.Description,
+			Parameters:  lhs.Record.Parameters,
+			LHS:         buildReportQueryRecord(lhs, data.LHSFolder, linkFolder),
+			RHS:         &rhsRecord,
+			Diff:        diff,
+		})
+	}
+
+	return report
+}
+
+func buildReportQueryRecord(record QueryRecordWithStats, folder string, linkFolder string) ReportQueryRecord {
+	reportQueryRecord := ReportQueryRecord{
+		Status:            record.Record.Status,
+		Error:             record.Record.Error,
+		Runs:              len(record.Record.ExecutionTimes),
+		ResultHash:        record.Record.ResultHash,
+		ResultRows:        record.Record.ResultRows,
+		MeasureRuns:       record.Record.MeasureRuns,
+		MeasureStopReason: record.Record.MeasureStopReason,
+		Stats:             record.Stats,
+	}
+
+	if len(record.Record.WarmupExecutionTimes) > 0 {
+		reportQueryRecord.WarmupStats = &record.WarmupStats
+	}
+
+	if hasColdRuns(record) {
+		reportQueryRecord.ColdStats = &record.ColdStats
+	}
+
+	if record.Record.Load != nil {
+		reportQueryRecord.LoadStats = &record.LoadStats
+	}
+
+	for _, collectorResult := range record.Record.CollectorResults {
+		for _, file := range collectorResult.Files {
+			if file.Type != collector.FileTypeFlamegraph {
+				continue
+			}
+
+			filePath := filepath.Join(folder,
+				record.Record.GetFolderName(),
+				collectorResult.Name,
+				file.Name,
+			)
+
+			if relativeFilePath, err := filepath.Rel(linkFolder, filePath); err == nil {
+				filePath = relativeFilePath
+			}
+
+			reportQueryRecord.Flamegraphs = append(reportQueryRecord.Flamegraphs, filepath.ToSlash(filePath))
+		}
+	}
+
+	return reportQueryRecord
+}
+
+func renderMarkdownReport(report ReportData) string {
+	var builder strings.Builder
+
+	if report.RHSFolder == "" {
+		fmt.Fprintf(&builder, "## Performance test results `%s`\n\n", report.LHSFolder)
+		builder.WriteString("| Query | Status | Server Median (ms) [95% CI] | Client Median (ms) [95% CI] " +
+			"| Server P90 (ms) | Server StdDev (ms) | Runs | Flamegraphs |\n")
+		builder.WriteString("|---|---|---|---|---|---|---|---|\n")
+
+		for _, query := range report.Queries {
+			queryStats := query.LHS.Stats
+			serverConfidenceInterval := queryStats.GetMedianServerDurationConfidenceIntervalMilliseconds()
+			clientConfidenceInterval := queryStats.GetMedianClientDurationConfidenceIntervalMilliseconds()
+
+			// Durations of failed query are partial, so status is shown next to them
+			status := "ok"
+			if query.LHS.Status != "" {
+				status = "**" + query.LHS.Status + "**"
+			}
+
+			fmt.Fprintf(&builder, "| %s | %s | %.2f %s | %.2f %s | %.2f | %.2f | %d | %s |\n",
+				query.GetName(),
+				status,
+				queryStats.GetMedianServerDurationMilliseconds(),
+				serverConfidenceInterval.FormatBounds("%.2f"),
+				queryStats.GetMedianClientDurationMilliseconds(),
+				clientConfidenceInterval.FormatBounds("%.2f"),
+				queryStats.ServerDurationDistribution.GetP90DurationMilliseconds(),
+				queryStats.GetStdDevServerDurationMilliseconds(),
+				query.LHS.Runs,
+				getMarkdownFlamegraphLinks("", query.LHS.Flamegraphs),
+			)
+		}
+	} else {
+		renderMarkdownDiffReport(&builder, report)
+	}
+
+	builder.WriteString("\n<details>\n<summary>Queries</summary>\n\n")
+	for _, query := range report.Queries {
+		fmt.Fprintf(&builder, "Query %s:\n```sql\n%s\n```\n\n", query.GetName(), strings.TrimSpace(query.Query))
+	}
+	builder.WriteString("</details>\n")
+
+	return builder.String()
+}
+
+func renderMarkdownDiffReport(builder *strings.Builder, report ReportData) {
+	suiteSummary := report.SuiteSummary
+
+	fmt.Fprintf(builder, "## Performance difference `%s` vs `%s`\n\n", report.LHSFolder, report.RHSFolder)
+	builder.WriteString("| Queries | Geometric Mean Ratio [95% CI] | LHS Total (ms) | RHS Total (ms) | Total Diff " +
+		"| Improvements | Regressions | Unchanged |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|---|\n")
+	fmt.Fprintf(builder, "| %d | %.3f %s | %.2f | %.2f | %+.2f%% | %d | %d | %d |\n\n",
+		suiteSummary.Queries,
+		suiteSummary.GeometricMeanRatio,
+		suiteSummary.GeometricMeanRatioConfidenceInterval.FormatBounds("%.3f"),
+		suiteSummary.GetLHSTotalDurationMilliseconds(),
+		suiteSummary.GetRHSTotalDurationMilliseconds(),
+		suiteSummary.GetRelativeTotalDurationDiff(),
+		suiteSummary.Improvements,
+		suiteSummary.Regressions,
+		suiteSummary.Unchanged,
+	)
+
+	if len(report.ResultMismatchQueries) > 0 {
+		fmt.Fprintf(builder, "**Warning:** queries %v results differ between LHS and RHS.\n\n",
+			report.ResultMismatchQueries,
+		)
+	}
+
+	builder.WriteString("| Query | LHS Median (ms) | RHS Median (ms) | Diff [95% CI] | P-Value | Change " +
+		"| Flamegraphs |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|\n")
+
+	for _, query := range report.Queries {
+		lhsMedian := query.LHS.Stats.GetMedianServerDurationMilliseconds()
+		rhsMedian := query.RHS.Stats.GetMedianServerDurationMilliseconds()
+		relativeDiff := query.Diff.RelativeMedianServerDurationDiff
+		comparison := query.Diff.ServerDurationComparison
+		if query.Diff.ClientDurationsCompared {
+			lhsMedian = query.LHS.Stats.GetMedianClientDurationMilliseconds()
+			rhsMedian = query.RHS.Stats.GetMedianClientDurationMilliseconds()
+			relativeDiff = query.Diff.RelativeMedianClientDurationDiff
+			comparison = query.Diff.ClientDurationComparison
+		}
+
+		fmt.Fprintf(builder, "| %s | %.2f | %.2f | %+.2f%% %s | %.4f | %s | %s |\n",
+			query.GetName(),
+			lhsMedian,
+			rhsMedian,
+			relativeDiff,
+			comparison.RelativeMedianDiffConfidenceInterval.FormatBounds("%+.2f%%"),
+			comparison.MannWhitneyPValue,
+			getMarkdownChange(query),
+			strings.TrimSpace(getMarkdownFlamegraphLinks("LHS ", query.LHS.Flamegraphs)+" "+
+				getMarkdownFlamegraphLinks("RHS ", query.RHS.Flamegraphs)),
+		)
+	}
+}
+
+// getMarkdownChange returns change of query, or statuses of failed query sides, because durations of failed query are
+// partial. Changes other than unchanged are highlighted.
+func getMarkdownChange(query ReportQuery) string {
+	statuses := []string{}
+	if query.LHS.Status != "" {
+		statuses = append(statuses, "LHS "+query.LHS.Status)
+	}
+	if query.RHS.Status != "" {
+		statuses = append(statuses, "RHS "+query.RHS.Status)
+	}
+
+	if len(statuses) > 0 {
+		return "**" + strings.Join(statuses, ", ") + "**"
+	}
+
+	if query.Diff.Change == stats.ChangeUnchanged {
+		return string(query.Diff.Change)
+	}
+
+	return "**" + string(query.Diff.Change) + "**"
+}
+
+func getMarkdownFlamegraphLinks(prefix string, flamegraphs []string) string {
+	links := make([]string, 0, len(flamegraphs))
+	for _, flamegraph := range flamegraphs {
+		links = append(links, fmt.Sprintf("[%s%s](<%s>)", prefix, filepath.Base(flamegraph), flamegraph))
+	}
+
+	return strings.Join(links, " ")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/kitaisreal/paw/internal/logger"
+)
+
+// prepareOutputPath creates output path, existing output path is removed after confirmation unless recording is
+// resumed.
+func prepareOutputPath(outputPath string) {
+	if resume {
+		createDirectoryOrExit(outputPath)
+		return
+	}
+
+	createOutputPath(outputPath)
+}
+
+// prepareConfigurationFiles copies configuration files into output path. If recording is resumed and output path
+// already contains configuration files, they must match current configuration files.
+func prepareConfigurationFiles(configPath string, testFilePath string, outputPath string) {
+	if resume {
+		if _, err := os.Stat(filepath.Join(outputPath, "test_file.yaml")); err == nil {
+			err := checkConfigurationFiles(configPath, testFilePath, outputPath)
+			if err != nil {
+				logger.Log.Errorf("Failed to resume recording in %s: %v", outputPath, err)
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
+	copyConfigurationFiles(configPath, testFilePath, outputPath)
+}
+
+// prepareProfilesFile writes profiles file into output path. If recording is resumed and output path already contains
+// profiles file, it must contain same profiles.
+func prepareProfilesFile(outputPath string, profiles []string) {
+	if resume {
+		recordedProfiles, err := readProfilesFile(outputPath)
+		if err != nil {
+			logger.Log.Errorf("Failed to resume recording in %s: %v", outputPath, err)
+			os.Exit(1)
+		}
+
+		if recordedProfiles != nil && !slices.Equal(recordedProfiles, profiles) {
+			logger.Log.Errorf("Failed to resume recording in %s: recorded profiles %v differ from profiles %v",
+				outputPath,
+				recordedProfiles,
+				profiles,
+			)
+			os.Exit(1)
+		}
+	}
+
+	writeProfilesFile(outputPath, profiles)
+}
+
+func checkConfigurationFiles(configPath string, testFilePath string, outputPath string) error {
+	err := checkFilesEqual(testFilePath, filepath.Join(outputPath, "test_file.yaml"))
+	if err != nil {
+		return fmt.Errorf("test file differs from recorded test file: %w", err)
+	}
+
+	recordedConfigPath := filepath.Join(outputPath, "config.yaml")
+
+	if configPath == "" {
+		if _, err := os.Stat(recordedConfigPath); err == nil {
+			return fmt.Errorf("config file is not specified, but recording used config file %s", recordedConfigPath)
+		}
+
+		return nil
+	}
+
+	err = checkFilesEqual(configPath, recordedConfigPath)
+	if err != nil {
+		return fmt.Errorf("config file differs from recorded config file: %w", err)
+	}
+
+	return nil
+}
+
+func checkFilesEqual(path string, recordedPath string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	recordedContent, err := os.ReadFile(recordedPath)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(content, recordedContent) {
+		return fmt.Errorf("content of %s and %s differs", path, recordedPath)
+	}
+
+	return nil
+}
+
+// isQueryRecorded returns true if all query folders contain complete query records. Query record is written after
+// all query runs and collectors are finished, so query is recorded if its record can be parsed and query did not
+// fail.
+func isQueryRecorded(queryDirNames []string) bool {
+	for _, queryDirName := range queryDirNames {
+		queryRecord, err := deserializeQueryRecord(filepath.Join(queryDirName, "query_record.json"))
+		if err != nil || queryRecord.IsFailed() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/kitaisreal/paw/internal/driver"
+)
+
+var (
+	errQueryTimeout = errors.New("query timeout exceeded")
+	errTestTimeout  = errors.New("test timeout exceeded")
+	errInterrupted  = errors.New("recording interrupted")
+)
+
+// buildRecordContext returns context that is cancelled on SIGINT or SIGTERM or after test timeout. Context cause
+// contains reason of cancellation. After first signal default signal handling is restored, so second signal
+// terminates paw immediately.
+func buildRecordContext(settings config.Settings) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case recordSignal := <-signalChan:
+			cancel(fmt.Errorf("%w by signal %v", errInterrupted, recordSignal))
+		case <-ctx.Done():
+		}
+
+		signal.Stop(signalChan)
+	}()
+
+	if settings.TestTimeout <= 0 {
+		return ctx, func() { cancel(nil) }
+	}
+
+	timeoutCtx, timeoutCancel := context.WithTimeoutCause(ctx,
+		settings.TestTimeout,
+		fmt.Errorf("%w: %v", errTestTimeout, settings.TestTimeout),
+	)
+
+	return timeoutCtx, func() {
+		timeoutCancel()
+		cancel(nil)
+	}
+}
+
+// timeoutDriver cancels query run if it exceeds timeout.
+type timeoutDriver struct {
+	driver  driver.Driver
+	timeout time.Duration
+}
+
+func withQueryTimeout(drv driver.Driver, timeout time.Duration) driver.Driver {
+	if timeout <= 0 {
+		return drv
+	}
+
+	return &timeoutDriver{driver: drv, timeout: timeout}
+}
+
+func (d *timeoutDriver) Run(ctx context.Context, query string) (driver.ExecutionTime, error) {
+	runCtx, cancel := context.WithTimeoutCause(ctx, d.timeout, errQueryTimeout)
+	defer cancel()
+
+	executionTime, err := d.driver.Run(runCtx, query)
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(runCtx), errQueryTimeout) {
+		return executionTime, fmt.Errorf("%w: query run exceeded %v: %w", errQueryTimeout, d.timeout, err)
+	}
+
+	return executionTime, err
+}
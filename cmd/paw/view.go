@@ -5,10 +5,11 @@ import (
 	"embed"
 	"fmt"
 	"html/template"
-	"math"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,13 +26,30 @@ import (
 type ViewHTMLPages struct {
 	IndexHTML                     string
 	QueryNumberToQueryDetailsHTML map[int]string
+	CollectorFiles                []ViewCollectorFile
 }
 
 func View(cmd *cobra.Command, args []string) {
+	if len(args) == 1 {
+		folder := convertPathToFolder(args[0])
+
+		profileFolders, err := getProfileFolders(folder)
+		if err != nil {
+			logger.Log.Errorf("Failed to read profiles of folder %s: %v", folder, err)
+			os.Exit(1)
+		}
+
+		if len(profileFolders) > 0 {
+			args = profileFolders
+		}
+	}
+
 	if len(args) == 1 {
 		ViewSingle(cmd, args)
 	} else if len(args) == 2 {
 		ViewDiff(cmd, args)
+	} else if len(args) > 2 {
+		ViewMulti(cmd, args)
 	} else {
 		logger.Log.Errorf("Invalid number of arguments: %d", len(args))
 		os.Exit(1)
@@ -42,8 +60,13 @@ func ViewSingle(_ *cobra.Command, args []string) {
 	folder := convertPathToFolder(args[0])
 	viewSingleHTMLPages := buildViewSingleHTMLPages(folder)
 
+	if exportPath != "" {
+		exportViewOrExit(viewSingleHTMLPages, []string{folder})
+		return
+	}
+
 	logger.Log.Infof("Viewing performance test results from folder: %s using port: %d", folder, port)
-	runViewServer(viewSingleHTMLPages, folder, "")
+	runViewServer(viewSingleHTMLPages, []string{folder})
 }
 
 func ViewDiff(_ *cobra.Command, args []string) {
@@ -51,15 +74,30 @@ func ViewDiff(_ *cobra.Command, args []string) {
 	rhsFolder := convertPathToFolder(args[1])
 	viewHTMLPages := buildViewDiffHTMLPages(lhsFolder, rhsFolder)
 
+	if exportPath != "" {
+		exportViewOrExit(viewHTMLPages, []string{lhsFolder, rhsFolder})
+		return
+	}
+
 	logger.Log.Infof("Viewing performance difference test results from folders lhs: %s and rhs: %s using port: %d",
 		lhsFolder,
 		rhsFolder,
 		port,
 	)
-	runViewServer(viewHTMLPages, lhsFolder, rhsFolder)
+	runViewServer(viewHTMLPages, []string{lhsFolder, rhsFolder})
 }
 
-func runViewServer(viewHTMLPages ViewHTMLPages, lhsFolder string, rhsFolder string) {
+func exportViewOrExit(viewHTMLPages ViewHTMLPages, folders []string) {
+	err := exportView(viewHTMLPages, exportPath, folders)
+	if err != nil {
+		logger.Log.Errorf("Failed to export view to %s: %v", exportPath, err)
+		os.Exit(1)
+	}
+
+	logger.Log.Infof("Exported view to %s", exportPath)
+}
+
+func runViewServer(viewHTMLPages ViewHTMLPages, folders []string) {
 	staticHandler := http.FileServer(http.FS(staticFS))
 	http.Handle("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasSuffix(r.URL.Path, ".css") {
@@ -100,26 +138,20 @@ func runViewServer(viewHTMLPages ViewHTMLPages, lhsFolder string, rhsFolder stri
 			return
 		}
 
-		var folder string
-
-		if folderStr == "lhs" {
-			folder = lhsFolder
-		} else if folderStr == "rhs" {
-			folder = rhsFolder
-		} else {
+		folder, ok := getViewFolder(folders, folderStr)
+		if !ok {
 			http.Error(w, "Invalid folder parameter", http.StatusBadRequest)
 			return
 		}
 
-		queryNumberStr := queryParams.Get("query")
-		if queryNumberStr == "" {
+		queryFolderName := queryParams.Get("query")
+		if queryFolderName == "" {
 			http.Error(w, "Missing query parameter", http.StatusBadRequest)
 			return
 		}
 
-		queryNumber, err := strconv.ParseUint(queryNumberStr, 10, 64)
-		if err != nil {
-			http.Error(w, "Invalid query number", http.StatusBadRequest)
+		if !strings.HasPrefix(queryFolderName, queryFolderPrefix) || filepath.Base(queryFolderName) != queryFolderName {
+			http.Error(w, "Invalid query folder", http.StatusBadRequest)
 			return
 		}
 
@@ -135,7 +167,7 @@ func runViewServer(viewHTMLPages ViewHTMLPages, lhsFolder string, rhsFolder stri
 			return
 		}
 
-		filePath := filepath.Join(folder, fmt.Sprintf("query_%d", queryNumber), collectorName, filename)
+		filePath := getCollectorFilePath(folder, queryFolderName, collectorName, filename)
 		http.ServeFile(w, r, filePath)
 	})
 
@@ -155,24 +187,31 @@ func runViewServer(viewHTMLPages ViewHTMLPages, lhsFolder string, rhsFolder stri
 }
 
 type ViewSingleData struct {
-	FolderName string
-	Records    []QueryRecordWithStats
+	FolderName  string
+	Records     []QueryRecordWithStats
+	HasColdRuns bool
 }
 
-func buildViewSingleHTMLPages(folder string) ViewHTMLPages {
+func buildViewSingleData(folder string) ViewSingleData {
 	records, err := parseTestFolder(folder)
 	if err != nil {
 		logger.Log.Errorf("Failed to parse test folder %s: %v", folder, err)
 		os.Exit(1)
 	}
 
-	data := ViewSingleData{
-		FolderName: folder,
-		Records:    records,
+	return ViewSingleData{
+		FolderName:  folder,
+		Records:     records,
+		HasColdRuns: slices.ContainsFunc(records, hasColdRuns),
 	}
+}
+
+func buildViewSingleHTMLPages(folder string) ViewHTMLPages {
+	data := buildViewSingleData(folder)
+	records := data.Records
 
 	viewSingleHTMLBuffer := bytes.NewBuffer(nil)
-	err = viewSingleTemplate.ExecuteTemplate(viewSingleHTMLBuffer, "base.html", data)
+	err := viewSingleTemplate.ExecuteTemplate(viewSingleHTMLBuffer, "base.html", data)
 	if err != nil {
 		logger.Log.Errorf("Failed to execute template: %v", err)
 		os.Exit(1)
@@ -205,9 +244,15 @@ func buildViewSingleHTMLPages(folder string) ViewHTMLPages {
 
 	wg.Wait()
 
+	collectorFiles := []ViewCollectorFile{}
+	for _, record := range records {
+		collectorFiles = append(collectorFiles, getCollectorFiles("lhs", record)...)
+	}
+
 	return ViewHTMLPages{
 		IndexHTML:                     viewSingleHTML,
 		QueryNumberToQueryDetailsHTML: queryNumberToHTMLPage,
+		CollectorFiles:                collectorFiles,
 	}
 }
 
@@ -215,9 +260,18 @@ type ViewDiffData struct {
 	LHSFolder        string
 	RHSFolder        string
 	QueryRecordPairs []QueryRecordPairWithStats
+	// Queries are specified by id, or by query number if query has no id
+	ResultMismatchQueries []string
+	QueryMismatchQueryIDs []string
+	StartedFailingQueries []string
+	StoppedFailingQueries []string
+	HasColdRuns           bool
+	SuiteSummary          stats.SuiteSummary
 }
 
-func buildViewDiffHTMLPages(lhsFolder string, rhsFolder string) ViewHTMLPages {
+const suiteSummaryTopQueries = 5
+
+func buildViewDiffData(lhsFolder string, rhsFolder string) ViewDiffData {
 	lhsRecords, err := parseTestFolder(lhsFolder)
 	if err != nil {
 		logger.Log.Errorf("Failed to parse lhs test folder %s: %v", lhsFolder, err)
@@ -231,14 +285,69 @@ func buildViewDiffHTMLPages(lhsFolder string, rhsFolder string) ViewHTMLPages {
 	}
 
 	queryRecordPairs := buildQueryRecordsDiff(lhsRecords, rhsRecords)
+
+	resultMismatchQueries := []string{}
+	for _, queryRecordPair := range queryRecordPairs {
+		if queryRecordPair.IsResultMismatch() {
+			resultMismatchQueries = append(resultMismatchQueries, queryRecordPair.LHS.Record.GetName())
+		}
+	}
+
+	if len(resultMismatchQueries) > 0 {
+		logger.Log.Warnf("Queries %v results differ between lhs and rhs", resultMismatchQueries)
+	}
+
+	queryMismatchQueryIDs := []string{}
+	for _, queryRecordPair := range queryRecordPairs {
+		if queryRecordPair.IsQueryMismatch() {
+			queryMismatchQueryIDs = append(queryMismatchQueryIDs, queryRecordPair.LHS.Record.ID)
+		}
+	}
+
+	if len(queryMismatchQueryIDs) > 0 {
+		logger.Log.Warnf("Queries %v text differs between lhs and rhs", queryMismatchQueryIDs)
+	}
+
+	startedFailingQueries, stoppedFailingQueries := []string{}, []string{}
+	for _, queryRecordPair := range queryRecordPairs {
+		if queryRecordPair.IsStartedFailing() {
+			startedFailingQueries = append(startedFailingQueries, queryRecordPair.LHS.Record.GetName())
+		} else if queryRecordPair.IsStoppedFailing() {
+			stoppedFailingQueries = append(stoppedFailingQueries, queryRecordPair.LHS.Record.GetName())
+		}
+	}
+
+	if len(startedFailingQueries) > 0 {
+		logger.Log.Warnf("Queries %v started failing in rhs", startedFailingQueries)
+	}
+
 	viewData := ViewDiffData{
-		LHSFolder:        lhsFolder,
-		RHSFolder:        rhsFolder,
-		QueryRecordPairs: queryRecordPairs,
+		LHSFolder:             lhsFolder,
+		RHSFolder:             rhsFolder,
+		QueryRecordPairs:      queryRecordPairs,
+		ResultMismatchQueries: resultMismatchQueries,
+		QueryMismatchQueryIDs: queryMismatchQueryIDs,
+		StartedFailingQueries: startedFailingQueries,
+		StoppedFailingQueries: stoppedFailingQueries,
+		HasColdRuns: slices.ContainsFunc(queryRecordPairs, func(queryRecordPair QueryRecordPairWithStats) bool {
+			return hasColdRuns(queryRecordPair.LHS) && hasColdRuns(queryRecordPair.RHS)
+		}),
+		SuiteSummary: stats.GetSuiteSummary(buildSuiteQueries(queryRecordPairs),
+			significanceLevel,
+			minRelativeDiff,
+			suiteSummaryTopQueries,
+		),
 	}
 
+	return viewData
+}
+
+func buildViewDiffHTMLPages(lhsFolder string, rhsFolder string) ViewHTMLPages {
+	viewData := buildViewDiffData(lhsFolder, rhsFolder)
+	queryRecordPairs := viewData.QueryRecordPairs
+
 	viewDiffHTMLBuffer := bytes.NewBuffer(nil)
-	err = viewDiffTemplate.ExecuteTemplate(viewDiffHTMLBuffer, "base.html", viewData)
+	err := viewDiffTemplate.ExecuteTemplate(viewDiffHTMLBuffer, "base.html", viewData)
 	if err != nil {
 		logger.Log.Errorf("Failed to execute template: %v", err)
 		os.Exit(1)
@@ -271,9 +380,16 @@ func buildViewDiffHTMLPages(lhsFolder string, rhsFolder string) ViewHTMLPages {
 
 	wg.Wait()
 
+	collectorFiles := []ViewCollectorFile{}
+	for _, queryRecordPair := range queryRecordPairs {
+		collectorFiles = append(collectorFiles, getCollectorFiles("lhs", queryRecordPair.LHS)...)
+		collectorFiles = append(collectorFiles, getCollectorFiles("rhs", queryRecordPair.RHS)...)
+	}
+
 	return ViewHTMLPages{
 		IndexHTML:                     viewDiffHTML,
 		QueryNumberToQueryDetailsHTML: queryNumberToHTMLPage,
+		CollectorFiles:                collectorFiles,
 	}
 }
 
@@ -281,33 +397,36 @@ func buildQueryRecordsDiff(
 	lhsRecords []QueryRecordWithStats,
 	rhsRecords []QueryRecordWithStats,
 ) []QueryRecordPairWithStats {
-	lhsMap := map[int]QueryRecordWithStats{}
-	for _, lhsRecord := range lhsRecords {
-		lhsMap[lhsRecord.Record.QueryNumber] = lhsRecord
+	queryPairs := []QueryRecordPairWithStats{}
+	for _, queryRecordGroup := range buildQueryRecordGroups([][]QueryRecordWithStats{lhsRecords, rhsRecords}) {
+		queryPairs = append(queryPairs, QueryRecordPairWithStats{LHS: queryRecordGroup[0], RHS: queryRecordGroup[1]})
 	}
 
-	queryPairs := []QueryRecordPairWithStats{}
-	for _, rhsRecord := range rhsRecords {
-		if lhsRecord, ok := lhsMap[rhsRecord.Record.QueryNumber]; ok {
-			queryPairs = append(queryPairs, QueryRecordPairWithStats{LHS: lhsRecord, RHS: rhsRecord})
+	return queryPairs
+}
+
+func buildSuiteQueries(queryRecordPairs []QueryRecordPairWithStats) []stats.SuiteQuery {
+	suiteQueries := []stats.SuiteQuery{}
+	for _, queryRecordPair := range queryRecordPairs {
+		if queryRecordPair.IsFailed() {
+			continue
 		}
-	}
 
-	sort.Slice(queryPairs, func(i, j int) bool {
-		return queryPairs[i].LHS.Record.QueryNumber < queryPairs[j].LHS.Record.QueryNumber
-	})
+		suiteQueries = append(suiteQueries, stats.SuiteQuery{
+			QueryNumber: queryRecordPair.LHS.Record.QueryNumber,
+			ID:          queryRecordPair.LHS.Record.ID,
+			Query:       queryRecordPair.LHS.Record.Query,
+			LHSTimes:    queryRecordPair.LHS.Record.ExecutionTimes,
+			RHSTimes:    queryRecordPair.RHS.Record.ExecutionTimes,
+		})
+	}
 
-	return queryPairs
+	return suiteQueries
 }
 
 func parseTestFolder(folder string) ([]QueryRecordWithStats, error) {
 	var records []QueryRecordWithStats
 
-	const (
-		queryFolderPrefix = "query_"
-		queryRecordFile   = "query_record.json"
-	)
-
 	files, err := os.ReadDir(folder)
 	if err != nil {
 		return nil, fmt.Errorf("error reading directory %s: %w", folder, err)
@@ -318,9 +437,10 @@ func parseTestFolder(folder string) ([]QueryRecordWithStats, error) {
 			continue
 		}
 
-		queryIndexStr := strings.TrimPrefix(file.Name(), queryFolderPrefix)
-		_, err := strconv.ParseUint(queryIndexStr, 10, 64)
-		if err != nil {
+		// Query folder name is query number or query id
+		queryFolderSuffix := strings.TrimPrefix(file.Name(), queryFolderPrefix)
+		_, err := strconv.ParseUint(queryFolderSuffix, 10, 64)
+		if err != nil && !config.IsValidQueryID(queryFolderSuffix) {
 			continue
 		}
 
@@ -330,9 +450,20 @@ func parseTestFolder(folder string) ([]QueryRecordWithStats, error) {
 			return nil, fmt.Errorf("error reading query record file %s: %w", queryRecordPath, err)
 		}
 
+		var loadStats stats.LoadStats
+		if queryRecord.Load != nil {
+			loadStats = stats.GetLoadStats(queryRecord.Load.ExecutionTimes,
+				queryRecord.Load.ErrorCount,
+				queryRecord.Load.Duration,
+			)
+		}
+
 		records = append(records, QueryRecordWithStats{
-			Record: queryRecord,
-			Stats:  stats.GetStats(queryRecord.ExecutionTimes),
+			Record:      queryRecord,
+			Stats:       stats.GetStats(queryRecord.ExecutionTimes),
+			WarmupStats: stats.GetStats(queryRecord.WarmupExecutionTimes),
+			ColdStats:   stats.GetStats(queryRecord.ColdExecutionTimes),
+			LoadStats:   loadStats,
 		})
 	}
 
@@ -343,6 +474,10 @@ func parseTestFolder(folder string) ([]QueryRecordWithStats, error) {
 	return records, nil
 }
 
+func hasColdRuns(record QueryRecordWithStats) bool {
+	return len(record.Record.ColdExecutionTimes) > 0
+}
+
 func convertPathToFolder(path string) string {
 	if strings.HasSuffix(path, ".yaml") {
 		test, err := config.ParseTestFileYaml(path)
@@ -368,31 +503,11 @@ var (
 	viewSingleQueryDetailsTemplate *template.Template
 	viewDiffTemplate               *template.Template
 	viewDiffQueryDetailsTemplate   *template.Template
+	viewMultiTemplate              *template.Template
+	viewMultiQueryDetailsTemplate  *template.Template
 )
 
 func init() {
-	var getRelativeDiff = func(lhs, rhs float64) float64 {
-		if lhs == 0 {
-			lhs = 1e-6
-		}
-
-		return (rhs - lhs) / lhs * 100
-	}
-
-	var getMedianRowClass = func(lhs, rhs float64) string {
-		relativeDifference := getRelativeDiff(lhs, rhs)
-
-		if math.Abs(relativeDifference) > 5 {
-			if relativeDifference > 0 {
-				return "significant-negative-diff"
-			}
-
-			return "significant-positive-diff"
-		}
-
-		return ""
-	}
-
 	funcMap := template.FuncMap{
 		"add": func(a, b int) int {
 			return a + b
@@ -436,9 +551,6 @@ func init() {
 		"getRelativeMedianServerDurationDiff": func(lhs stats.Stats, rhs stats.Stats) float64 {
 			return getRelativeDiff(lhs.GetMedianServerDurationMilliseconds(), rhs.GetMedianServerDurationMilliseconds())
 		},
-		"getMedianServerDurationRowClass": func(lhs, rhs stats.Stats) string {
-			return getMedianRowClass(lhs.GetMedianServerDurationMilliseconds(), rhs.GetMedianServerDurationMilliseconds())
-		},
 		"getClientDurationMilliseconds": func(executionTime driver.ExecutionTime) float64 {
 			return float64(executionTime.ClientDuration) / 1e6
 		},
@@ -460,9 +572,16 @@ func init() {
 		"getRelativeMedianClientDurationDiff": func(lhs stats.Stats, rhs stats.Stats) float64 {
 			return getRelativeDiff(lhs.GetMedianClientDurationMilliseconds(), rhs.GetMedianClientDurationMilliseconds())
 		},
-		"getMedianClientDurationRowClass": func(lhs, rhs stats.Stats) string {
-			return getMedianRowClass(lhs.GetMedianClientDurationMilliseconds(), rhs.GetMedianClientDurationMilliseconds())
-		},
+	}
+
+	for _, statsFuncMap := range []template.FuncMap{
+		statisticsFuncMap,
+		distributionStatsFuncMap,
+		loadStatsFuncMap,
+		metricStatsFuncMap,
+		linkFuncMap,
+	} {
+		maps.Copy(funcMap, statsFuncMap)
 	}
 
 	var buildTemplate = func(pageTemplate string) *template.Template {
@@ -484,4 +603,6 @@ func init() {
 	viewSingleQueryDetailsTemplate = buildTemplate("templates/view_single_query_details.html")
 	viewDiffTemplate = buildTemplate("templates/view_diff.html")
 	viewDiffQueryDetailsTemplate = buildTemplate("templates/view_diff_query_details.html")
+	viewMultiTemplate = buildTemplate("templates/view_multi.html")
+	viewMultiQueryDetailsTemplate = buildTemplate("templates/view_multi_query_details.html")
 }
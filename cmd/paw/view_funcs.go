@@ -0,0 +1,280 @@
+package main
+
+import (
+	"html/template"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/kitaisreal/paw/internal/stats"
+)
+
+func getRelativeDiff(lhs, rhs float64) float64 {
+	if lhs == 0 {
+		lhs = 1e-6
+	}
+
+	return (rhs - lhs) / lhs * 100
+}
+
+func getMedianRowClass(lhs, rhs float64) string {
+	relativeDifference := getRelativeDiff(lhs, rhs)
+
+	if math.Abs(relativeDifference) > 5 {
+		if relativeDifference > 0 {
+			return "significant-negative-diff"
+		}
+
+		return "significant-positive-diff"
+	}
+
+	return ""
+}
+
+// getSignificantMedianRowClass highlights duration difference only if it is statistically significant and large
+// enough to matter.
+func getSignificantMedianRowClass(lhs, rhs float64, comparison stats.Comparison) string {
+	switch stats.ClassifyChange(getRelativeDiff(lhs, rhs), comparison, significanceLevel, minRelativeDiff) {
+	case stats.ChangeRegression:
+		return "significant-negative-diff"
+	case stats.ChangeImprovement:
+		return "significant-positive-diff"
+	default:
+		return ""
+	}
+}
+
+// DurationComparison compares median durations of query in milliseconds, server durations are compared if both sides
+// have them, otherwise client durations, same as in suite summary.
+type DurationComparison struct {
+	ComparedDuration stats.ComparedDuration
+	LHSMedian        float64
+	RHSMedian        float64
+	RelativeDiff     float64
+	Comparison       stats.Comparison
+}
+
+func compareQueryDurations(lhsStats stats.Stats,
+	rhsStats stats.Stats,
+	lhsTimes []driver.ExecutionTime,
+	rhsTimes []driver.ExecutionTime,
+) DurationComparison {
+	durationComparison := DurationComparison{ComparedDuration: stats.GetComparedDuration(lhsTimes, rhsTimes)}
+
+	if durationComparison.ComparedDuration == stats.ComparedDurationServer {
+		durationComparison.LHSMedian = lhsStats.GetMedianServerDurationMilliseconds()
+		durationComparison.RHSMedian = rhsStats.GetMedianServerDurationMilliseconds()
+		durationComparison.Comparison = stats.CompareServerDurations(lhsTimes, rhsTimes)
+	} else {
+		durationComparison.LHSMedian = lhsStats.GetMedianClientDurationMilliseconds()
+		durationComparison.RHSMedian = rhsStats.GetMedianClientDurationMilliseconds()
+		durationComparison.Comparison = stats.CompareClientDurations(lhsTimes, rhsTimes)
+	}
+
+	durationComparison.RelativeDiff = getRelativeDiff(durationComparison.LHSMedian, durationComparison.RHSMedian)
+
+	return durationComparison
+}
+
+// getComparedDurationTitle returns compared duration name for table headers.
+func getComparedDurationTitle(comparedDuration stats.ComparedDuration) string {
+	switch comparedDuration {
+	case stats.ComparedDurationClient:
+		return "Client"
+	case stats.ComparedDurationMixed:
+		return "Server or Client"
+	default:
+		return "Server"
+	}
+}
+
+var statisticsFuncMap = template.FuncMap{
+	"getLHSTotalDurationMilliseconds": func(s stats.SuiteSummary) float64 {
+		return s.GetLHSTotalDurationMilliseconds()
+	},
+	"getRHSTotalDurationMilliseconds": func(s stats.SuiteSummary) float64 {
+		return s.GetRHSTotalDurationMilliseconds()
+	},
+	"getRelativeTotalDurationDiff": func(s stats.SuiteSummary) float64 {
+		return s.GetRelativeTotalDurationDiff()
+	},
+	"getMedianServerDurationConfidenceIntervalMilliseconds": func(s stats.Stats) stats.ConfidenceInterval {
+		return s.GetMedianServerDurationConfidenceIntervalMilliseconds()
+	},
+	"getMedianClientDurationConfidenceIntervalMilliseconds": func(s stats.Stats) stats.ConfidenceInterval {
+		return s.GetMedianClientDurationConfidenceIntervalMilliseconds()
+	},
+	"getDurationComparison":    compareQueryDurations,
+	"getComparedDurationTitle": getComparedDurationTitle,
+	"getDurationComparisonRowClass": func(durationComparison DurationComparison) string {
+		return getSignificantMedianRowClass(durationComparison.LHSMedian,
+			durationComparison.RHSMedian,
+			durationComparison.Comparison,
+		)
+	},
+	"getServerDurationComparison": func(lhs, rhs []driver.ExecutionTime) stats.Comparison {
+		return stats.CompareServerDurations(lhs, rhs)
+	},
+	"getMedianServerDurationRowClass": func(lhs, rhs stats.Stats, comparison stats.Comparison) string {
+		return getSignificantMedianRowClass(lhs.GetMedianServerDurationMilliseconds(),
+			rhs.GetMedianServerDurationMilliseconds(),
+			comparison,
+		)
+	},
+	"getClientDurationComparison": func(lhs, rhs []driver.ExecutionTime) stats.Comparison {
+		return stats.CompareClientDurations(lhs, rhs)
+	},
+	"getPairedServerDurationComparison": func(lhs, rhs []driver.ExecutionTime) stats.PairedComparison {
+		return stats.ComparePairedServerDurations(lhs, rhs)
+	},
+	"getPairedClientDurationComparison": func(lhs, rhs []driver.ExecutionTime) stats.PairedComparison {
+		return stats.ComparePairedClientDurations(lhs, rhs)
+	},
+	"getMedianClientDurationRowClass": func(lhs, rhs stats.Stats, comparison stats.Comparison) string {
+		return getSignificantMedianRowClass(lhs.GetMedianClientDurationMilliseconds(),
+			rhs.GetMedianClientDurationMilliseconds(),
+			comparison,
+		)
+	},
+}
+
+var loadStatsFuncMap = template.FuncMap{
+	"getDurationSeconds": func(duration time.Duration) float64 {
+		return duration.Seconds()
+	},
+	"getMinLoadClientDurationMilliseconds": func(s stats.LoadStats) float64 {
+		return s.GetMinClientDurationMilliseconds()
+	},
+	"getMedianLoadClientDurationMilliseconds": func(s stats.LoadStats) float64 {
+		return s.GetMedianClientDurationMilliseconds()
+	},
+	"getP90LoadClientDurationMilliseconds": func(s stats.LoadStats) float64 {
+		return s.GetP90ClientDurationMilliseconds()
+	},
+	"getP99LoadClientDurationMilliseconds": func(s stats.LoadStats) float64 {
+		return s.GetP99ClientDurationMilliseconds()
+	},
+	"getMaxLoadClientDurationMilliseconds": func(s stats.LoadStats) float64 {
+		return s.GetMaxClientDurationMilliseconds()
+	},
+}
+
+var metricStatsFuncMap = template.FuncMap{
+	"getMetricNames": func(statsList ...stats.Stats) []string {
+		metricNames := []string{}
+		for _, s := range statsList {
+			for name := range s.Metrics {
+				if !slices.Contains(metricNames, name) {
+					metricNames = append(metricNames, name)
+				}
+			}
+		}
+
+		sort.Strings(metricNames)
+
+		return metricNames
+	},
+	"getMetricStats": func(s stats.Stats, name string) stats.MetricStats {
+		return s.Metrics[name]
+	},
+	"getRelativeMedianMetricDiff": func(lhs stats.Stats, rhs stats.Stats, name string) float64 {
+		return getRelativeDiff(float64(lhs.Metrics[name].Median), float64(rhs.Metrics[name].Median))
+	},
+	"getMedianMetricRowClass": func(lhs stats.Stats, rhs stats.Stats, name string) string {
+		return getMedianRowClass(float64(lhs.Metrics[name].Median), float64(rhs.Metrics[name].Median))
+	},
+}
+
+const (
+	histogramChartWidth  = 600
+	histogramChartHeight = 120
+)
+
+type HistogramChartBar struct {
+	X                 float64
+	Y                 float64
+	Width             float64
+	Height            float64
+	Count             uint64
+	StartMilliseconds float64
+	EndMilliseconds   float64
+}
+
+type HistogramChart struct {
+	Width           int
+	Height          int
+	Bars            []HistogramChartBar
+	MinMilliseconds float64
+	MaxMilliseconds float64
+}
+
+func getHistogramChart(histogram stats.Histogram) HistogramChart {
+	chart := HistogramChart{
+		Width:           histogramChartWidth,
+		Height:          histogramChartHeight,
+		MinMilliseconds: float64(histogram.Min) / 1e6,
+		MaxMilliseconds: float64(histogram.Min+histogram.BucketWidth*time.Duration(len(histogram.Counts))) / 1e6,
+	}
+
+	maxCount := uint64(0)
+	for _, count := range histogram.Counts {
+		maxCount = max(maxCount, count)
+	}
+
+	if maxCount == 0 {
+		return chart
+	}
+
+	barWidth := float64(histogramChartWidth) / float64(len(histogram.Counts))
+
+	for i, count := range histogram.Counts {
+		barHeight := float64(count) / float64(maxCount) * histogramChartHeight
+		bucketStart := histogram.Min + histogram.BucketWidth*time.Duration(i)
+
+		chart.Bars = append(chart.Bars, HistogramChartBar{
+			X:                 barWidth * float64(i),
+			Y:                 histogramChartHeight - barHeight,
+			Width:             barWidth,
+			Height:            barHeight,
+			Count:             count,
+			StartMilliseconds: float64(bucketStart) / 1e6,
+			EndMilliseconds:   float64(bucketStart+histogram.BucketWidth) / 1e6,
+		})
+	}
+
+	return chart
+}
+
+var distributionStatsFuncMap = template.FuncMap{
+	"getP90DurationMilliseconds": func(s stats.DistributionStats) float64 {
+		return s.GetP90DurationMilliseconds()
+	},
+	"getP95DurationMilliseconds": func(s stats.DistributionStats) float64 {
+		return s.GetP95DurationMilliseconds()
+	},
+	"getP99DurationMilliseconds": func(s stats.DistributionStats) float64 {
+		return s.GetP99DurationMilliseconds()
+	},
+	"getMADDurationMilliseconds": func(s stats.DistributionStats) float64 {
+		return s.GetMADDurationMilliseconds()
+	},
+	"getOutliersCount": func(s stats.DistributionStats) int {
+		return s.GetOutliersCount()
+	},
+	"getOutlierDescription": func(s stats.Stats, index int) string {
+		outlierDurations := []string{}
+
+		if index < len(s.ServerDurationDistribution.Outliers) && s.ServerDurationDistribution.Outliers[index] {
+			outlierDurations = append(outlierDurations, "server")
+		}
+		if index < len(s.ClientDurationDistribution.Outliers) && s.ClientDurationDistribution.Outliers[index] {
+			outlierDurations = append(outlierDurations, "client")
+		}
+
+		return strings.Join(outlierDurations, ", ")
+	},
+	"getHistogramChart": getHistogramChart,
+}
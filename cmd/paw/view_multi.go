@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/kitaisreal/paw/internal/logger"
+	"github.com/kitaisreal/paw/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+type ViewMultiData struct {
+	Folders       []string
+	BaselineIndex int
+	Queries       []ViewMultiQuery
+}
+
+type ViewMultiQuery struct {
+	QueryNumber   int
+	Query         string
+	BaselineIndex int
+	Runs          []ViewMultiQueryRun
+}
+
+// ViewMultiQueryRun contains query record from one folder and its server duration difference with baseline folder.
+type ViewMultiQueryRun struct {
+	QueryRecordWithStats
+	Folder string
+	// FolderIndex is used as folder parameter of collector file links
+	FolderIndex string
+	IsBaseline  bool
+	// IsBest and IsWorst are set for runs with smallest and largest median server duration, if medians differ
+	IsBest                           bool
+	IsWorst                          bool
+	RelativeMedianServerDurationDiff float64
+	ServerDurationComparison         stats.Comparison
+}
+
+func ViewMulti(_ *cobra.Command, args []string) {
+	folders := make([]string, len(args))
+	for i, arg := range args {
+		folders[i] = convertPathToFolder(arg)
+	}
+
+	if baselineIndex < 0 || baselineIndex >= len(folders) {
+		logger.Log.Errorf("Invalid baseline index: %d, expected index in range [0, %d)", baselineIndex, len(folders))
+		os.Exit(1)
+	}
+
+	viewHTMLPages := buildViewMultiHTMLPages(folders, baselineIndex)
+
+	if exportPath != "" {
+		exportViewOrExit(viewHTMLPages, folders)
+		return
+	}
+
+	logger.Log.Infof("Viewing performance difference test results from folders: %v with baseline: %s using port: %d",
+		folders,
+		folders[baselineIndex],
+		port,
+	)
+	runViewServer(viewHTMLPages, folders)
+}
+
+func buildViewMultiData(folders []string, baselineIndex int) ViewMultiData {
+	folderRecords := make([][]QueryRecordWithStats, len(folders))
+	for i, folder := range folders {
+		records, err := parseTestFolder(folder)
+		if err != nil {
+			logger.Log.Errorf("Failed to parse test folder %s: %v", folder, err)
+			os.Exit(1)
+		}
+
+		folderRecords[i] = records
+	}
+
+	data := ViewMultiData{Folders: folders, BaselineIndex: baselineIndex, Queries: []ViewMultiQuery{}}
+
+	for _, queryRecordGroup := range buildQueryRecordGroups(folderRecords) {
+		baselineRecord := queryRecordGroup[baselineIndex]
+
+		for i, record := range queryRecordGroup {
+			if record.Record.ID != "" && record.Record.Query != baselineRecord.Record.Query {
+				logger.Log.Warnf("Query %s text differs between %s and baseline %s",
+					record.Record.ID,
+					folders[i],
+					folders[baselineIndex],
+				)
+			}
+		}
+
+		query := ViewMultiQuery{
+			QueryNumber:   baselineRecord.Record.QueryNumber,
+			Query:         baselineRecord.Record.Query,
+			BaselineIndex: baselineIndex,
+		}
+
+		// Failed runs contain partial execution times, so they are not considered best or worst
+		bestIndex, worstIndex := -1, -1
+		for i, record := range queryRecordGroup {
+			query.Runs = append(query.Runs, ViewMultiQueryRun{
+				QueryRecordWithStats: record,
+				Folder:               folders[i],
+				FolderIndex:          strconv.Itoa(i),
+				IsBaseline:           i == baselineIndex,
+				RelativeMedianServerDurationDiff: getRelativeDiff(
+					baselineRecord.Stats.GetMedianServerDurationMilliseconds(),
+					record.Stats.GetMedianServerDurationMilliseconds(),
+				),
+				ServerDurationComparison: stats.CompareServerDurations(baselineRecord.Record.ExecutionTimes,
+					record.Record.ExecutionTimes,
+				),
+			})
+
+			if record.Record.IsFailed() {
+				continue
+			}
+
+			medianServerDuration := record.Stats.MedianServerDuration
+			if bestIndex < 0 || medianServerDuration < queryRecordGroup[bestIndex].Stats.MedianServerDuration {
+				bestIndex = i
+			}
+
+			if worstIndex < 0 || medianServerDuration > queryRecordGroup[worstIndex].Stats.MedianServerDuration {
+				worstIndex = i
+			}
+		}
+
+		if bestIndex >= 0 &&
+			queryRecordGroup[bestIndex].Stats.MedianServerDuration < queryRecordGroup[worstIndex].Stats.MedianServerDuration {
+			query.Runs[bestIndex].IsBest = true
+			query.Runs[worstIndex].IsWorst = true
+		}
+
+		data.Queries = append(data.Queries, query)
+	}
+
+	return data
+}
+
+func buildViewMultiHTMLPages(folders []string, baselineIndex int) ViewHTMLPages {
+	viewData := buildViewMultiData(folders, baselineIndex)
+
+	viewMultiHTMLBuffer := bytes.NewBuffer(nil)
+	err := viewMultiTemplate.ExecuteTemplate(viewMultiHTMLBuffer, "base.html", viewData)
+	if err != nil {
+		logger.Log.Errorf("Failed to execute template: %v", err)
+		os.Exit(1)
+	}
+
+	viewMultiHTML := viewMultiHTMLBuffer.String()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	queryNumberToHTMLPage := map[int]string{}
+
+	for _, query := range viewData.Queries {
+		wg.Add(1)
+
+		go func(query ViewMultiQuery) {
+			defer wg.Done()
+
+			queryDetailsPageHTMLBuffer := bytes.NewBuffer(nil)
+			err := viewMultiQueryDetailsTemplate.ExecuteTemplate(queryDetailsPageHTMLBuffer, "base.html", query)
+			if err != nil {
+				logger.Log.Errorf("Failed to execute template: %v", err)
+				os.Exit(1)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			queryNumberToHTMLPage[query.QueryNumber] = queryDetailsPageHTMLBuffer.String()
+		}(query)
+	}
+
+	wg.Wait()
+
+	collectorFiles := []ViewCollectorFile{}
+	for _, query := range viewData.Queries {
+		for _, run := range query.Runs {
+			collectorFiles = append(collectorFiles, getCollectorFiles(run.FolderIndex, run.QueryRecordWithStats)...)
+		}
+	}
+
+	return ViewHTMLPages{
+		IndexHTML:                     viewMultiHTML,
+		QueryNumberToQueryDetailsHTML: queryNumberToHTMLPage,
+		CollectorFiles:                collectorFiles,
+	}
+}
+
+// buildQueryRecordGroups returns records of queries that are recorded in all folders, each group contains query
+// records in folders order. Queries with id are matched by id, other queries are matched by query number.
+func buildQueryRecordGroups(folderRecords [][]QueryRecordWithStats) [][]QueryRecordWithStats {
+	queryFolderNameToGroup := map[string][]QueryRecordWithStats{}
+
+	for i, records := range folderRecords {
+		for _, record := range records {
+			queryFolderName := record.Record.GetFolderName()
+
+			// Query is skipped if it is not recorded in one of previous folders
+			group := queryFolderNameToGroup[queryFolderName]
+			if len(group) != i {
+				continue
+			}
+
+			queryFolderNameToGroup[queryFolderName] = append(group, record)
+		}
+	}
+
+	queryRecordGroups := [][]QueryRecordWithStats{}
+	for _, group := range queryFolderNameToGroup {
+		if len(group) == len(folderRecords) {
+			queryRecordGroups = append(queryRecordGroups, group)
+		}
+	}
+
+	sort.Slice(queryRecordGroups, func(i, j int) bool {
+		return queryRecordGroups[i][0].Record.QueryNumber < queryRecordGroups[j][0].Record.QueryNumber
+	})
+
+	return queryRecordGroups
+}
+
+// getViewFolder returns folder by folder parameter of collector file link, that is lhs or rhs in diff view and
+// folder index in multiple folders view.
+func getViewFolder(folders []string, folderParam string) (string, bool) {
+	switch folderParam {
+	case "lhs":
+		folderParam = "0"
+	case "rhs":
+		folderParam = "1"
+	}
+
+	folderIndex, err := strconv.Atoi(folderParam)
+	if err != nil || folderIndex < 0 || folderIndex >= len(folders) {
+		return "", false
+	}
+
+	return folders[folderIndex], true
+}
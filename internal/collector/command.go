@@ -0,0 +1,19 @@
+package collector
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// NewCommand returns command that is run in its own process group. When ctx is done whole process group is killed,
+// so processes started by shell, such as perf or offcputime, do not outlive cancelled collector or cold run.
+func NewCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	return cmd
+}
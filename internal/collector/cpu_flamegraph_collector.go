@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/kitaisreal/paw/internal/collector/flamegraph"
@@ -83,11 +82,15 @@ func (c *CPUFlamegraphCollector) Collect(
 		ExecutionTimes: []driver.ExecutionTime{},
 	}
 
-	waitChan := make(chan error)
+	// Collector commands are killed if query run fails or ctx is done
+	collectCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	waitChan := make(chan error, 1)
 
 	go func() {
 		pawDataFileName := filepath.Join(c.tempDir, "paw.perf.data")
-		perfRecordCmd := exec.CommandContext(ctx,
+		perfRecordCmd := NewCommand(collectCtx,
 			"perf",
 			"record",
 			"-F",
@@ -117,7 +120,7 @@ func (c *CPUFlamegraphCollector) Collect(
 			c.stackCollapseScriptPath,
 			pawFoldedDataFileName,
 		)
-		foldPerfDataCmd := exec.CommandContext(ctx,
+		foldPerfDataCmd := NewCommand(collectCtx,
 			"sh",
 			"-c",
 			foldPerfDataCmdArg,
@@ -133,7 +136,7 @@ func (c *CPUFlamegraphCollector) Collect(
 		}
 
 		cpuFlamegraphOutputFilePath := filepath.Join(outputFolder, cpuFlameGraphCollectorOutputFile)
-		flameGraphCmd := exec.CommandContext(ctx,
+		flameGraphCmd := NewCommand(collectCtx,
 			"sh",
 			"-c",
 			fmt.Sprintf("%s %s > %s",
@@ -169,12 +172,14 @@ collectCPUFlamegraph:
 	for {
 		execTime, err := drv.Run(ctx, query)
 		if err != nil {
-			logger.Log.Errorf("Collector %s failed to run query '%v': %v",
+			cancel()
+			<-waitChan
+
+			return collectorResult, fmt.Errorf("collector %s failed to run query '%v': %w",
 				cpuFlameGraphCollectorName,
 				query,
 				err,
 			)
-			os.Exit(1)
 		}
 
 		collectorResult.ExecutionTimes = append(collectorResult.ExecutionTimes, execTime)
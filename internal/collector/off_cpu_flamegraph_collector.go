@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/kitaisreal/paw/internal/collector/flamegraph"
@@ -75,11 +74,15 @@ func (c *OffCPUFlamegraphCollector) Collect(
 		ExecutionTimes: []driver.ExecutionTime{},
 	}
 
-	waitChan := make(chan error)
+	// Collector commands are killed if query run fails or ctx is done
+	collectCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	waitChan := make(chan error, 1)
 
 	go func() {
 		stacksFileName := filepath.Join(c.tempDir, "paw.out.stacks")
-		offcputimeCmd := exec.CommandContext(ctx,
+		offcputimeCmd := NewCommand(collectCtx,
 			"sh",
 			"-c",
 			fmt.Sprintf("offcputime-bpfcc -df %d > %s",
@@ -99,7 +102,7 @@ func (c *OffCPUFlamegraphCollector) Collect(
 		}
 
 		offCPUFlamegraphOutputFilePath := filepath.Join(outputFolder, offCPUFlameGraphCollectorOutputFile)
-		flameGraphCmd := exec.CommandContext(ctx,
+		flameGraphCmd := NewCommand(collectCtx,
 			"sh",
 			"-c",
 			fmt.Sprintf("%s --color=io --title=\"Off-CPU Time Flame Graph\" --countname=us %s > %s",
@@ -136,12 +139,14 @@ collectOffCPUFlamegraph:
 	for {
 		execTime, err := drv.Run(ctx, query)
 		if err != nil {
-			logger.Log.Errorf("Collector %s failed to run query '%v': %v",
+			cancel()
+			<-waitChan
+
+			return collectorResult, fmt.Errorf("collector %s failed to run query '%v': %w",
 				offCPUFlameGraphCollectorName,
 				query,
 				err,
 			)
-			os.Exit(1)
 		}
 
 		collectorResult.ExecutionTimes = append(collectorResult.ExecutionTimes, execTime)
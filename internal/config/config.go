@@ -1,7 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"time"
 
 	"github.com/kitaisreal/paw/internal/collector"
 	"github.com/kitaisreal/paw/internal/driver"
@@ -20,20 +24,111 @@ type CollectorProfile struct {
 	Settings  collector.Settings `yaml:"settings"`
 }
 
+type LoadSettings struct {
+	Concurrency       int     `yaml:"concurrency"`
+	QPS               float64 `yaml:"qps"`
+	DurationSeconds   int     `yaml:"duration_seconds"`
+	Runs              uint64  `yaml:"runs"`
+	CollectDuringLoad bool    `yaml:"collect_during_load"`
+}
+
+// ColdSettings specifies cold runs, before each cold run caches are dropped using shell commands and queries
+// executed by the profile driver.
+type ColdSettings struct {
+	Runs              uint64   `yaml:"runs"`
+	DropCacheCommands []string `yaml:"drop_cache_commands"`
+	DropCacheQueries  []string `yaml:"drop_cache_queries"`
+}
+
+// AdaptiveSettings specifies adaptive measurement, if max runs is specified query measure runs are executed until
+// median is stable according to targets, max runs are executed or time budget is exhausted, but at least min runs
+// are executed. Targets are specified in percents of median and mean respectively, zero target is not checked.
+type AdaptiveSettings struct {
+	MinRuns               uint64  `yaml:"min_runs"`
+	MaxRuns               uint64  `yaml:"max_runs"`
+	TimeBudgetSeconds     float64 `yaml:"time_budget_seconds"`
+	TargetRelativeCIWidth float64 `yaml:"target_relative_ci_width"`
+	TargetCV              float64 `yaml:"target_cv"`
+}
+
 type Settings struct {
-	QueryMeasureRuns uint64 `yaml:"query_measure_runs"`
+	QueryMeasureRuns          uint64           `yaml:"query_measure_runs"`
+	QueryWarmupRuns           uint64           `yaml:"query_warmup_runs"`
+	QueryFailOnResultMismatch bool             `yaml:"query_fail_on_result_mismatch"`
+	Adaptive                  AdaptiveSettings `yaml:"adaptive"`
+	Cold                      ColdSettings     `yaml:"cold"`
+	Load                      LoadSettings     `yaml:"load"`
+	// QueryTimeout limits duration of each query run, including query runs of collectors. TestTimeout limits duration
+	// of whole recording. Zero timeout is not checked.
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	TestTimeout  time.Duration `yaml:"test_timeout"`
+}
+
+// CompareSettings specifies when compare command fails, query fails if its median server duration regression in
+// percents is significant and larger than max regression. Query max regression overrides max regression for specific
+// queries, allowed queries never fail. Queries are specified by id, or by query number if query has no id.
+type CompareSettings struct {
+	SignificanceLevel  float64            `yaml:"significance_level"`
+	MaxRegression      float64            `yaml:"max_regression"`
+	QueryMaxRegression map[string]float64 `yaml:"query_max_regression"`
+	AllowedQueries     []string           `yaml:"allowed_queries"`
 }
 
 type Config struct {
 	Profiles          []Profile          `yaml:"profiles"`
 	CollectorProfiles []CollectorProfile `yaml:"collector_profiles"`
 	Settings          Settings           `yaml:"settings"`
+	Compare           CompareSettings    `yaml:"compare"`
+}
+
+// Query is specified in test file either as plain query string or as object with query and per query
+// settings overrides. Query with parameters is template, that is expanded into separate queries when test file is
+// parsed.
+type Query struct {
+	Query string `yaml:"query"`
+	// ID is stable query identifier, that is used instead of query position in test file for query folder name and to
+	// match queries of different test results
+	ID          string         `yaml:"id"`
+	Tags        []string       `yaml:"tags"`
+	Description string         `yaml:"description"`
+	WarmupRuns  *uint64        `yaml:"warmup_runs"`
+	Timeout     *time.Duration `yaml:"timeout"`
+	// Parameters values are substituted into {{name}} placeholders of query, query is expanded for each combination
+	// of parameters values. Each of parameter sets is combined with each combination of parameters values.
+	Parameters    map[string]ParameterValues `yaml:"parameters"`
+	ParameterSets []map[string]string        `yaml:"parameter_sets"`
+	// ParameterSet contains parameters values of expanded query
+	ParameterSet map[string]string `yaml:"-"`
+}
+
+func (q *Query) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&q.Query)
+	}
+
+	type rawQuery Query
+	return value.Decode((*rawQuery)(q))
+}
+
+// GetSettings returns settings with query overrides applied.
+func (q *Query) GetSettings(settings Settings) Settings {
+	if q.WarmupRuns != nil {
+		settings.QueryWarmupRuns = *q.WarmupRuns
+	}
+
+	if q.Timeout != nil {
+		settings.QueryTimeout = *q.Timeout
+	}
+
+	return settings
 }
 
 type Test struct {
 	Name       string   `yaml:"name"`
 	Collectors []string `yaml:"collectors"`
-	Queries    []string `yaml:"queries"`
+	// Profiles are recorded if profile is not specified in command line, each profile is recorded into own subfolder
+	Profiles []string `yaml:"profiles"`
+	Queries  []Query  `yaml:"queries"`
 }
 
 func CreateDefaultConfig() Config {
@@ -49,7 +144,44 @@ func ParseConfigFileYaml(path string) (Config, error) {
 }
 
 func ParseTestFileYaml(path string) (Test, error) {
-	return parseYamlFile[Test](path)
+	test, err := parseYamlFile[Test](path)
+	if err != nil {
+		return test, err
+	}
+
+	// Parameters values files are relative to test file
+	test.Queries, err = expandQueries(test.Queries, filepath.Dir(path))
+	if err != nil {
+		return test, err
+	}
+
+	queryIDs := map[string]bool{}
+	for _, query := range test.Queries {
+		if query.ID == "" {
+			continue
+		}
+
+		if !IsValidQueryID(query.ID) {
+			return test, fmt.Errorf("query id '%s' must start with letter and contain only letters, digits, '_', '.' "+
+				"and '-'", query.ID)
+		}
+
+		if queryIDs[query.ID] {
+			return test, fmt.Errorf("query id '%s' is specified multiple times", query.ID)
+		}
+
+		queryIDs[query.ID] = true
+	}
+
+	return test, nil
+}
+
+var queryIDRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// IsValidQueryID returns true if query id can be used as part of folder name. Query id starts with letter, so it
+// does not clash with query number.
+func IsValidQueryID(id string) bool {
+	return queryIDRegex.MatchString(id)
 }
 
 func parseYamlFile[T any](path string) (T, error) {
@@ -0,0 +1,209 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kitaisreal/paw/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, fileName string, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), fileName)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	return filePath
+}
+
+func TestParseTestFileQueryParameters(t *testing.T) {
+	testFilePath := writeTestFile(t, "test.yaml", `
+name: parameters
+queries:
+  - SELECT 1
+  - query: SELECT {{a}}, {{b}}
+    warmup_runs: 2
+    parameters:
+      b: [x, y]
+      a: [1, 2]
+  - query: SELECT {{a}}, {{b}}
+    parameter_sets:
+      - {a: 3, b: z}
+`)
+
+	result, err := config.ParseTestFileYaml(testFilePath)
+	require.NoError(t, err)
+
+	queries := []string{}
+	for _, query := range result.Queries {
+		queries = append(queries, query.Query)
+	}
+
+	require.Equal(t, []string{
+		"SELECT 1",
+		"SELECT 1, x",
+		"SELECT 1, y",
+		"SELECT 2, x",
+		"SELECT 2, y",
+		"SELECT 3, z",
+	}, queries)
+	require.Nil(t, result.Queries[0].ParameterSet)
+	require.Equal(t, map[string]string{"a": "2", "b": "x"}, result.Queries[3].ParameterSet)
+	require.Equal(t, uint64(2), *result.Queries[3].WarmupRuns)
+	require.Equal(t, map[string]string{"a": "3", "b": "z"}, result.Queries[5].ParameterSet)
+}
+
+func TestParseTestFileQueryParametersSinglePass(t *testing.T) {
+	testFilePath := writeTestFile(t, "test.yaml", `
+name: parameters
+queries:
+  - query: SELECT '{{a}}', '{{b}}'
+    parameter_sets:
+      - {a: "{{b}}", b: "{{a}}"}
+`)
+
+	result, err := config.ParseTestFileYaml(testFilePath)
+	require.NoError(t, err)
+	require.Len(t, result.Queries, 1)
+
+	// Placeholders in parameter values are kept as is
+	require.Equal(t, "SELECT '{{b}}', '{{a}}'", result.Queries[0].Query)
+}
+
+func TestParseTestFileQueryParametersFileAndRandom(t *testing.T) {
+	testFilePath := writeTestFile(t, "test.yaml", `
+name: parameters
+queries:
+  - query: SELECT {{id}} + {{value}}
+    parameters:
+      id:
+        file: ids.txt
+      value:
+        random: {seed: 42, count: 3, min: 10, max: 20}
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(testFilePath), "ids.txt"), []byte("1\n\n2\n"), 0644))
+
+	result, err := config.ParseTestFileYaml(testFilePath)
+	require.NoError(t, err)
+	require.Len(t, result.Queries, 6)
+
+	sameSeedResult, err := config.ParseTestFileYaml(testFilePath)
+	require.NoError(t, err)
+	require.Equal(t, result.Queries, sameSeedResult.Queries)
+
+	for i, query := range result.Queries {
+		require.Equal(t, []string{"1", "2"}[i/3], query.ParameterSet["id"])
+
+		value := query.ParameterSet["value"]
+		require.GreaterOrEqual(t, value, "10")
+		require.LessOrEqual(t, value, "20")
+	}
+}
+
+func TestParseTestFileQueryParametersErrors(t *testing.T) {
+	for _, content := range []string{
+		"queries:\n  - query: SELECT {{a}}\n    parameters:\n      b: [1]\n",
+		"queries:\n  - query: SELECT {{a}}\n    parameters:\n      a: []\n",
+		"queries:\n  - query: SELECT {{a}}\n    parameters:\n      a:\n        file: missing.txt\n",
+		"queries:\n  - query: SELECT {{a}}\n    parameters:\n      a:\n        random: {count: 1, min: 2, max: 1}\n",
+	} {
+		_, err := config.ParseTestFileYaml(writeTestFile(t, "test.yaml", content))
+		require.Error(t, err)
+	}
+}
+
+func TestParseTestFileQueryIDs(t *testing.T) {
+	testFilePath := writeTestFile(t, "test.yaml", `
+name: ids
+queries:
+  - SELECT 1
+  - query: SELECT 2
+    id: select_two
+    tags: [simple]
+    description: Selects two
+  - query: SELECT {{a}}
+    id: select_a
+    parameters:
+      a: [1, 2]
+`)
+
+	result, err := config.ParseTestFileYaml(testFilePath)
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, query := range result.Queries {
+		ids = append(ids, query.ID)
+	}
+
+	require.Equal(t, []string{"", "select_two", "select_a_1", "select_a_2"}, ids)
+	require.Equal(t, []string{"simple"}, result.Queries[1].Tags)
+	require.Equal(t, "Selects two", result.Queries[1].Description)
+}
+
+func TestParseTestFileQueryIDsParameterValues(t *testing.T) {
+	getIDs := func(values string) []string {
+		testFilePath := writeTestFile(t, "test.yaml", `
+name: ids
+queries:
+  - query: SELECT {{a}}, '{{b}}'
+    id: select
+    parameters:
+      a: `+values+`
+      b: [x, y z]
+`)
+
+		result, err := config.ParseTestFileYaml(testFilePath)
+		require.NoError(t, err)
+
+		ids := []string{}
+		for _, query := range result.Queries {
+			ids = append(ids, query.ID)
+		}
+
+		return ids
+	}
+
+	ids := getIDs("[1, 3]")
+	require.Equal(t, "select_1_x", ids[0])
+	require.Equal(t, "select_3_x", ids[2])
+
+	// Values that can not be used in query id are hashed
+	require.Regexp(t, "^select_[0-9a-f]{8}$", ids[1])
+	require.NotEqual(t, ids[1], ids[3])
+
+	// Inserted value does not change ids of other parameter sets
+	insertedIDs := getIDs("[1, 2, 3]")
+	require.Equal(t, []string{ids[0], ids[1]}, insertedIDs[:2])
+	require.Equal(t, []string{ids[2], ids[3]}, insertedIDs[4:])
+}
+
+func TestParseTestFileQueryIDsErrors(t *testing.T) {
+	for _, content := range []string{
+		"queries:\n  - query: SELECT 1\n    id: 1\n",
+		"queries:\n  - query: SELECT 1\n    id: a/b\n",
+		"queries:\n  - query: SELECT 1\n    id: a\n  - query: SELECT 2\n    id: a\n",
+	} {
+		_, err := config.ParseTestFileYaml(writeTestFile(t, "test.yaml", content))
+		require.Error(t, err)
+	}
+}
+
+func TestParseConfigFileCompareSettings(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", `
+compare:
+  max_regression: 5
+  query_max_regression:
+    3: 20
+    count_users: 10
+  allowed_queries: [7, select_two]
+`)
+
+	result, err := config.ParseConfigFileYaml(configFilePath)
+	require.NoError(t, err)
+
+	// Queries without id are specified by query number
+	require.Equal(t, map[string]float64{"3": 20, "count_users": 10}, result.Compare.QueryMaxRegression)
+	require.Equal(t, []string{"7", "select_two"}, result.Compare.AllowedQueries)
+}
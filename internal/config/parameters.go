@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxParameterSetIDLength limits length of expanded query id suffix that consists of parameter values, longer
+// suffixes are replaced by hash of parameter values.
+const maxParameterSetIDLength = 64
+
+var parameterSetIDRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
+// ParameterValues are specified either as list of values or as object with values file or random values generator.
+type ParameterValues struct {
+	Values []string
+	// File contains one value per line, empty lines are skipped
+	File   string                 `yaml:"file"`
+	Random *RandomParameterValues `yaml:"random"`
+}
+
+// RandomParameterValues generates count uniformly distributed integers in range [min, max], values are same for same
+// seed.
+type RandomParameterValues struct {
+	Seed  uint64 `yaml:"seed"`
+	Count int    `yaml:"count"`
+	Min   int64  `yaml:"min"`
+	Max   int64  `yaml:"max"`
+}
+
+func (p *ParameterValues) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		return value.Decode(&p.Values)
+	}
+
+	type rawParameterValues ParameterValues
+	return value.Decode((*rawParameterValues)(p))
+}
+
+// getValues returns parameter values, files are read relative to baseFolder.
+func (p *ParameterValues) getValues(baseFolder string) ([]string, error) {
+	switch {
+	case p.File != "":
+		filePath := p.File
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(baseFolder, filePath)
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading parameter values file %s: %w", filePath, err)
+		}
+
+		values := []string{}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				values = append(values, line)
+			}
+		}
+
+		return values, nil
+	case p.Random != nil:
+		if p.Random.Count <= 0 || p.Random.Min > p.Random.Max {
+			return nil, fmt.Errorf("random parameter values count %d must be positive and min %d must not be greater "+
+				"than max %d",
+				p.Random.Count,
+				p.Random.Min,
+				p.Random.Max,
+			)
+		}
+
+		generator := rand.New(rand.NewPCG(p.Random.Seed, 0))
+
+		values := make([]string, p.Random.Count)
+		for i := range values {
+			values[i] = strconv.FormatInt(p.Random.Min+generator.Int64N(p.Random.Max-p.Random.Min+1), 10)
+		}
+
+		return values, nil
+	default:
+		return p.Values, nil
+	}
+}
+
+// expandQueries returns queries with query templates replaced by query for each of their parameter sets. Expanded
+// query id is template id with parameter set id suffix, so ids do not change if parameter values are added or removed.
+func expandQueries(queries []Query, baseFolder string) ([]Query, error) {
+	expandedQueries := []Query{}
+
+	for _, query := range queries {
+		if len(query.Parameters) == 0 && len(query.ParameterSets) == 0 {
+			expandedQueries = append(expandedQueries, query)
+			continue
+		}
+
+		parameterSets, err := getParameterSets(query, baseFolder)
+		if err != nil {
+			return nil, fmt.Errorf("query '%s' parameters error: %w", query.Query, err)
+		}
+
+		for _, parameterSet := range parameterSets {
+			expandedQuery := query
+			if query.ID != "" {
+				expandedQuery.ID = query.ID + "_" + getParameterSetID(parameterSet)
+			}
+
+			expandedQuery.Parameters = nil
+			expandedQuery.ParameterSets = nil
+			expandedQuery.ParameterSet = parameterSet
+
+			// Parameters are replaced in single pass, so parameter values that contain placeholders are not expanded
+			replacements := []string{}
+			for _, name := range slices.Sorted(maps.Keys(parameterSet)) {
+				replacements = append(replacements, "{{"+name+"}}", parameterSet[name])
+			}
+
+			expandedQuery.Query = strings.NewReplacer(replacements...).Replace(expandedQuery.Query)
+
+			expandedQueries = append(expandedQueries, expandedQuery)
+		}
+	}
+
+	return expandedQueries, nil
+}
+
+// getParameterSetID returns parameter values joined with '_' in order of parameter names. If values contain
+// characters that can not be used in query id or are too long, hash of parameter names and values is returned.
+func getParameterSetID(parameterSet map[string]string) string {
+	names := slices.Sorted(maps.Keys(parameterSet))
+
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, parameterSet[name])
+	}
+
+	parameterSetID := strings.Join(values, "_")
+	if parameterSetID != "" && len(parameterSetID) <= maxParameterSetIDLength &&
+		parameterSetIDRegex.MatchString(parameterSetID) {
+		return parameterSetID
+	}
+
+	hash := fnv.New32a()
+	for _, name := range names {
+		_, _ = fmt.Fprintf(hash, "%s=%s\x00", name, parameterSet[name])
+	}
+
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// getParameterSets returns parameter sets of query template. Parameters are combined in order of their names, so
+// last parameter changes first.
+func getParameterSets(query Query, baseFolder string) ([]map[string]string, error) {
+	parameterSets := query.ParameterSets
+	if len(parameterSets) == 0 {
+		parameterSets = []map[string]string{{}}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(query.Parameters)) {
+		parameterValues := query.Parameters[name]
+
+		values, err := parameterValues.getValues(baseFolder)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s error: %w", name, err)
+		}
+
+		if len(values) == 0 {
+			return nil, fmt.Errorf("parameter %s has no values", name)
+		}
+
+		combinedParameterSets := []map[string]string{}
+		for _, parameterSet := range parameterSets {
+			for _, value := range values {
+				combinedParameterSet := maps.Clone(parameterSet)
+				combinedParameterSet[name] = value
+				combinedParameterSets = append(combinedParameterSets, combinedParameterSet)
+			}
+		}
+
+		parameterSets = combinedParameterSets
+	}
+
+	for _, parameterSet := range parameterSets {
+		for name := range parameterSet {
+			if !strings.Contains(query.Query, "{{"+name+"}}") {
+				return nil, fmt.Errorf("parameter %s is not used in query", name)
+			}
+		}
+	}
+
+	return parameterSets, nil
+}
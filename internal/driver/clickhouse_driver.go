@@ -2,6 +2,8 @@ package driver
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,87 +15,245 @@ import (
 )
 
 type ClickHouseDriver struct {
-	Host     string
-	Port     int
-	client   *http.Client
-	settings Settings
+	Host          string
+	Port          int
+	client        *http.Client
+	settings      Settings
+	profileEvents []string
+	queryLog      bool
+	flushLogs     bool
 }
 
 const (
-	clickhouseDriverName            = "clickhouse"
-	clickhouseDriverDefaultHost     = "127.0.0.1"
-	clickhouseDriverHostSettingName = "host"
-	clickhouseDriverDefaultPort     = 8123
-	clickhouseDriverPortSettingName = "port"
+	clickhouseDriverName                     = "clickhouse"
+	clickhouseDriverDefaultHost              = "127.0.0.1"
+	clickhouseDriverHostSettingName          = "host"
+	clickhouseDriverDefaultPort              = 8123
+	clickhouseDriverPortSettingName          = "port"
+	clickhouseDriverProfileEventsSettingName = "profile_events"
+	clickhouseDriverQueryLogSettingName      = "query_log"
+	clickhouseDriverFlushLogsSettingName     = "flush_logs"
+	clickhouseDriverQueryIDParameterName     = "query_id"
+	clickhouseDriverMemoryUsageMetricName    = "memory_usage"
+	clickhouseDriverQueryLogPollInterval     = 100 * time.Millisecond
+	clickhouseDriverQueryLogWaitTimeout      = 30 * time.Second
 )
 
-func NewClickHouseDriver(host string, port int, settings Settings) (*ClickHouseDriver, error) {
-	client := &http.Client{}
-	c := &ClickHouseDriver{Host: host, Port: port, client: client, settings: settings}
+// clickhouseDriverConnectionSettingNames are settings that are passed to query log requests, so they are run by
+// the same user in the same database as measured queries.
+var clickhouseDriverConnectionSettingNames = []string{"user", "password", "database"}
+
+func NewClickHouseDriver(host string,
+	port int,
+	settings Settings,
+	profileEvents []string,
+	queryLog bool,
+	flushLogs bool,
+) (*ClickHouseDriver, error) {
+	c := &ClickHouseDriver{
+		Host:          host,
+		Port:          port,
+		client:        &http.Client{},
+		settings:      settings,
+		profileEvents: profileEvents,
+		queryLog:      queryLog || len(profileEvents) > 0,
+		flushLogs:     flushLogs,
+	}
 	return c, nil
 }
 
 func (c *ClickHouseDriver) Run(ctx context.Context, command string) (ExecutionTime, error) {
-	queryURL, err := url.Parse(fmt.Sprintf("http://%s:%d/", c.Host, c.Port))
+	params := url.Values{}
+	for name, value := range c.settings {
+		params.Set(name, fmt.Sprintf("%v", value))
+	}
+
+	// Unique query id is required to find query in system.query_log
+	var queryID string
+	if c.queryLog {
+		var err error
+		queryID, err = generateQueryID()
+		if err != nil {
+			return ExecutionTime{}, fmt.Errorf("%s driver query id generate error: %w", clickhouseDriverName, err)
+		}
+
+		params.Set(clickhouseDriverQueryIDParameterName, queryID)
+	}
+
+	queryStartTime := time.Now()
+
+	resp, body, err := c.execute(ctx, command, params)
 	if err != nil {
-		return ExecutionTime{}, fmt.Errorf("%s driver url parse error: %w", clickhouseDriverName, err)
+		return ExecutionTime{}, err
 	}
 
-	params := queryURL.Query()
-	for name, value := range c.settings {
-		params.Set(name, fmt.Sprintf("%v", value))
+	clientDuration := time.Since(queryStartTime)
+
+	// Parse server duration and metrics from X-ClickHouse-Summary header
+	var serverDuration time.Duration
+	metrics := map[string]uint64{}
+
+	if summary := resp.Header.Get("X-ClickHouse-Summary"); summary != "" {
+		var summaryData map[string]any
+		if err := json.Unmarshal([]byte(summary), &summaryData); err == nil {
+			for name, valueAny := range summaryData {
+				value, err := strconv.ParseUint(fmt.Sprintf("%v", valueAny), 10, 64)
+				if err != nil {
+					continue
+				}
+
+				if name == "elapsed_ns" {
+					serverDuration = time.Duration(value) * time.Nanosecond
+					continue
+				}
+
+				metrics[name] = value
+			}
+		}
+	}
+
+	if queryID != "" {
+		err := c.collectQueryLogMetrics(ctx, queryID, metrics)
+		if err != nil {
+			return ExecutionTime{}, err
+		}
+	}
+
+	var hasher resultHasher
+	hasher.addLines(body)
+
+	return ExecutionTime{
+		ClientDuration: clientDuration,
+		ServerDuration: serverDuration,
+		Metrics:        metrics,
+		ResultHash:     hasher.resultHash(),
+		ResultRows:     hasher.rows,
+	}, nil
+}
+
+func (c *ClickHouseDriver) execute(ctx context.Context,
+	command string,
+	params url.Values,
+) (*http.Response, []byte, error) {
+	queryURL, err := url.Parse(fmt.Sprintf("http://%s:%d/", c.Host, c.Port))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s driver url parse error: %w", clickhouseDriverName, err)
 	}
+
 	queryURL.RawQuery = params.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, queryURL.String(), strings.NewReader(command))
 	if err != nil {
-		return ExecutionTime{}, fmt.Errorf("%s driver request create error: %w", clickhouseDriverName, err)
+		return nil, nil, fmt.Errorf("%s driver request create error: %w", clickhouseDriverName, err)
 	}
 
-	queryStartTime := time.Now()
-
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return ExecutionTime{}, fmt.Errorf("%s driver query error: %w", clickhouseDriverName, err)
+		return nil, nil, fmt.Errorf("%s driver query error: %w", clickhouseDriverName, err)
 	}
 	defer resp.Body.Close()
 
 	// Read full response body to ensure query completes
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return ExecutionTime{}, fmt.Errorf("%s driver response read error: %w", clickhouseDriverName, err)
+		return nil, nil, fmt.Errorf("%s driver response read error: %w", clickhouseDriverName, err)
 	}
 
-	clientDuration := time.Since(queryStartTime)
-
 	if resp.StatusCode != http.StatusOK {
-		return ExecutionTime{}, fmt.Errorf("%s driver query error: HTTP %d: %s",
+		return nil, nil, fmt.Errorf("%s driver query error: HTTP %d: %s",
 			clickhouseDriverName,
 			resp.StatusCode,
 			strings.TrimSpace(string(body)),
 		)
 	}
 
-	// Parse server duration from X-ClickHouse-Summary header
-	var serverDuration time.Duration
-	if summary := resp.Header.Get("X-ClickHouse-Summary"); summary != "" {
-		var summaryData struct {
-			ElapsedNs string `json:"elapsed_ns"`
+	return resp, body, nil
+}
+
+// collectQueryLogMetrics adds memory usage and selected ProfileEvents of finished query from system.query_log.
+// Lookup is done outside of measured query time. Query log is flushed by server every flush_interval_milliseconds,
+// so lookup is retried until query appears in it, unless logs are explicitly flushed after each run.
+func (c *ClickHouseDriver) collectQueryLogMetrics(ctx context.Context,
+	queryID string,
+	metrics map[string]uint64,
+) error {
+	params := url.Values{}
+	for _, name := range clickhouseDriverConnectionSettingNames {
+		if value, ok := c.settings[name]; ok {
+			params.Set(name, fmt.Sprintf("%v", value))
 		}
-		if err := json.Unmarshal([]byte(summary), &summaryData); err == nil {
-			if elapsedNs, err := strconv.ParseUint(summaryData.ElapsedNs, 10, 64); err == nil {
-				serverDuration = time.Duration(elapsedNs) * time.Nanosecond
-			}
+	}
+
+	if c.flushLogs {
+		_, _, err := c.execute(ctx, "SYSTEM FLUSH LOGS", params)
+		if err != nil {
+			return fmt.Errorf("%s driver flush logs error: %w", clickhouseDriverName, err)
+		}
+	}
+
+	queryLogQuery := fmt.Sprintf("SELECT memory_usage, ProfileEvents FROM system.query_log "+
+		"WHERE query_id = '%s' AND type = 'QueryFinish' LIMIT 1 FORMAT JSONEachRow",
+		queryID,
+	)
+	params.Set("output_format_json_quote_64bit_integers", "0")
+
+	waitDeadline := time.Now().Add(clickhouseDriverQueryLogWaitTimeout)
+
+	var body []byte
+	for {
+		var err error
+		_, body, err = c.execute(ctx, queryLogQuery, params)
+		if err != nil {
+			return fmt.Errorf("%s driver query log read error: %w", clickhouseDriverName, err)
+		}
+
+		if len(strings.TrimSpace(string(body))) != 0 {
+			break
+		}
+
+		if c.flushLogs || time.Now().After(waitDeadline) {
+			return fmt.Errorf("%s driver query %s not found in query log", clickhouseDriverName, queryID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(clickhouseDriverQueryLogPollInterval):
 		}
 	}
 
-	return ExecutionTime{ClientDuration: clientDuration, ServerDuration: serverDuration}, nil
+	var queryLogData struct {
+		MemoryUsage   uint64            `json:"memory_usage"`
+		ProfileEvents map[string]uint64 `json:"ProfileEvents"`
+	}
+	if err := json.Unmarshal(body, &queryLogData); err != nil {
+		return fmt.Errorf("%s driver query log parse error: %w", clickhouseDriverName, err)
+	}
+
+	metrics[clickhouseDriverMemoryUsageMetricName] = queryLogData.MemoryUsage
+	for _, profileEvent := range c.profileEvents {
+		metrics[profileEvent] = queryLogData.ProfileEvents[profileEvent]
+	}
+
+	return nil
+}
+
+func generateQueryID() (string, error) {
+	queryIDBytes := make([]byte, 16)
+	if _, err := rand.Read(queryIDBytes); err != nil {
+		return "", err
+	}
+
+	return "paw-" + hex.EncodeToString(queryIDBytes), nil
 }
 
 func init() {
 	RegisterDriver(clickhouseDriverName, func(settings Settings) (Driver, error) {
 		host := clickhouseDriverDefaultHost
 		port := clickhouseDriverDefaultPort
+		profileEvents := []string{}
+		queryLog := false
+		flushLogs := false
 
 		if hostAny, ok := settings[clickhouseDriverHostSettingName]; ok {
 			host, ok = hostAny.(string)
@@ -113,14 +273,58 @@ func init() {
 				)
 			}
 		}
+		if profileEventsAny, ok := settings[clickhouseDriverProfileEventsSettingName]; ok {
+			profileEventsList, ok := profileEventsAny.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is not list",
+					clickhouseDriverName,
+					clickhouseDriverProfileEventsSettingName,
+				)
+			}
+
+			for _, profileEventAny := range profileEventsList {
+				profileEvent, ok := profileEventAny.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s driver profile setting '%s' contains non string value",
+						clickhouseDriverName,
+						clickhouseDriverProfileEventsSettingName,
+					)
+				}
+
+				profileEvents = append(profileEvents, profileEvent)
+			}
+		}
+
+		if queryLogAny, ok := settings[clickhouseDriverQueryLogSettingName]; ok {
+			queryLog, ok = queryLogAny.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is not bool",
+					clickhouseDriverName,
+					clickhouseDriverQueryLogSettingName,
+				)
+			}
+		}
+		if flushLogsAny, ok := settings[clickhouseDriverFlushLogsSettingName]; ok {
+			flushLogs, ok = flushLogsAny.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is not bool",
+					clickhouseDriverName,
+					clickhouseDriverFlushLogsSettingName,
+				)
+			}
+		}
 
 		driverSettings := Settings{}
 		for name, value := range settings {
-			if name != clickhouseDriverHostSettingName && name != clickhouseDriverPortSettingName {
+			if name != clickhouseDriverHostSettingName &&
+				name != clickhouseDriverPortSettingName &&
+				name != clickhouseDriverProfileEventsSettingName &&
+				name != clickhouseDriverQueryLogSettingName &&
+				name != clickhouseDriverFlushLogsSettingName {
 				driverSettings[name] = value
 			}
 		}
 
-		return NewClickHouseDriver(host, port, driverSettings)
+		return NewClickHouseDriver(host, port, driverSettings, profileEvents, queryLog, flushLogs)
 	})
 }
@@ -0,0 +1,175 @@
+package driver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/stretchr/testify/require"
+)
+
+type clickHouseRequest struct {
+	query  string
+	params url.Values
+}
+
+type clickHouseStandIn struct {
+	port     int
+	mutex    sync.Mutex
+	requests []clickHouseRequest
+}
+
+func (s *clickHouseStandIn) getRequests() []clickHouseRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests
+}
+
+// runClickHouseStandIn answers queries with summary header and two result rows. Query log lookups return
+// query log row only after the given number of empty responses, queries containing 'error' fail with HTTP 500.
+func runClickHouseStandIn(t *testing.T, emptyQueryLogResponses int) *clickHouseStandIn {
+	standIn := &clickHouseStandIn{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := string(body)
+
+		standIn.mutex.Lock()
+		standIn.requests = append(standIn.requests, clickHouseRequest{query: query, params: r.URL.Query()})
+		queryLogEmpty := strings.Contains(query, "system.query_log") && emptyQueryLogResponses > 0
+		if queryLogEmpty {
+			emptyQueryLogResponses--
+		}
+		standIn.mutex.Unlock()
+
+		switch {
+		case strings.Contains(query, "error"):
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, "Code: 62. DB::Exception: Syntax error")
+		case strings.HasPrefix(query, "SYSTEM FLUSH LOGS"):
+		case queryLogEmpty:
+		case strings.Contains(query, "system.query_log"):
+			_, _ = io.WriteString(w, `{"memory_usage":4096,"ProfileEvents":{"SelectedMarks":3,"MarkCacheMisses":1}}`+"\n")
+		default:
+			w.Header().Set("X-ClickHouse-Summary",
+				`{"read_rows":"10","read_bytes":"80","result_rows":"2","memory_usage":"1024","elapsed_ns":"12750000"}`,
+			)
+			_, _ = io.WriteString(w, "1\n2\n")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	standIn.port = server.Listener.Addr().(*net.TCPAddr).Port
+	return standIn
+}
+
+func TestClickHouseDriverSummary(t *testing.T) {
+	standIn := runClickHouseStandIn(t, 0)
+
+	drv, err := driver.CreateDriver("clickhouse", driver.Settings{"port": standIn.port, "max_threads": 16})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT number FROM numbers(2)")
+	require.NoError(t, err)
+	require.Equal(t, 12750*time.Microsecond, executionTime.ServerDuration)
+	require.Positive(t, executionTime.ClientDuration)
+	require.Equal(t, map[string]uint64{
+		"read_rows":    10,
+		"read_bytes":   80,
+		"result_rows":  2,
+		"memory_usage": 1024,
+	}, executionTime.Metrics)
+	require.Equal(t, uint64(2), executionTime.ResultRows)
+
+	// Query log is not used by default
+	requests := standIn.getRequests()
+	require.Len(t, requests, 1)
+	require.Equal(t, "16", requests[0].params.Get("max_threads"))
+	require.False(t, requests[0].params.Has("query_id"))
+}
+
+func TestClickHouseDriverQueryLog(t *testing.T) {
+	standIn := runClickHouseStandIn(t, 0)
+
+	drv, err := driver.CreateDriver("clickhouse", driver.Settings{
+		"port":           standIn.port,
+		"user":           "paw",
+		"password":       "secret",
+		"database":       "test",
+		"max_threads":    16,
+		"flush_logs":     true,
+		"profile_events": []any{"SelectedMarks"},
+	})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT number FROM numbers(2)")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4096), executionTime.Metrics["memory_usage"])
+	require.Equal(t, uint64(3), executionTime.Metrics["SelectedMarks"])
+	require.NotContains(t, executionTime.Metrics, "MarkCacheMisses")
+
+	requests := standIn.getRequests()
+	require.Len(t, requests, 3)
+
+	queryID := requests[0].params.Get("query_id")
+	require.NotEmpty(t, queryID)
+	require.Equal(t, "SYSTEM FLUSH LOGS", requests[1].query)
+	require.Contains(t, requests[2].query, "WHERE query_id = '"+queryID+"'")
+
+	// Query log requests are run with the same connection settings, but without query settings
+	for _, request := range requests {
+		require.Equal(t, "paw", request.params.Get("user"))
+		require.Equal(t, "secret", request.params.Get("password"))
+		require.Equal(t, "test", request.params.Get("database"))
+	}
+	require.False(t, requests[1].params.Has("max_threads"))
+	require.False(t, requests[2].params.Has("max_threads"))
+}
+
+func TestClickHouseDriverQueryLogWait(t *testing.T) {
+	standIn := runClickHouseStandIn(t, 2)
+
+	drv, err := driver.CreateDriver("clickhouse", driver.Settings{"port": standIn.port, "query_log": true})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT number FROM numbers(2)")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4096), executionTime.Metrics["memory_usage"])
+
+	// Logs are not flushed, lookup is retried until query appears in query log
+	requests := standIn.getRequests()
+	require.Len(t, requests, 4)
+	for _, request := range requests[1:] {
+		require.Contains(t, request.query, "system.query_log")
+	}
+}
+
+func TestClickHouseDriverQueryError(t *testing.T) {
+	standIn := runClickHouseStandIn(t, 0)
+
+	drv, err := driver.CreateDriver("clickhouse", driver.Settings{"port": standIn.port})
+	require.NoError(t, err)
+
+	_, err = drv.Run(context.Background(), "SELECT error")
+	require.ErrorContains(t, err, "clickhouse driver query error: HTTP 500: Code: 62. DB::Exception: Syntax error")
+}
+
+func TestClickHouseDriverInvalidSetting(t *testing.T) {
+	_, err := driver.CreateDriver("clickhouse", driver.Settings{"port": "8123"})
+	require.ErrorContains(t, err, "clickhouse driver profile setting 'port' is not int")
+
+	_, err = driver.CreateDriver("clickhouse", driver.Settings{"query_log": "true"})
+	require.ErrorContains(t, err, "clickhouse driver profile setting 'query_log' is not bool")
+
+	_, err = driver.CreateDriver("clickhouse", driver.Settings{"profile_events": []any{1}})
+	require.ErrorContains(t, err, "clickhouse driver profile setting 'profile_events' contains non string value")
+}
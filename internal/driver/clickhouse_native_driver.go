@@ -0,0 +1,260 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ClickHouse/ch-go"
+	"github.com/ClickHouse/ch-go/proto"
+)
+
+type ClickHouseNativeDriver struct {
+	Host       string
+	Port       int
+	options    ch.Options
+	client     *ch.Client
+	settings   []ch.Setting
+	resultHash bool
+	// result blocks are reused by next runs of the same command
+	result        clickHouseNativeResult
+	resultCommand string
+}
+
+const (
+	clickhouseNativeDriverName                  = "clickhouse_native"
+	clickhouseNativeDriverDefaultHost           = "127.0.0.1"
+	clickhouseNativeDriverHostSettingName       = "host"
+	clickhouseNativeDriverDefaultPort           = 9000
+	clickhouseNativeDriverPortSettingName       = "port"
+	clickhouseNativeDriverUserSettingName       = "user"
+	clickhouseNativeDriverPasswordSettingName   = "password"
+	clickhouseNativeDriverDatabaseSettingName   = "database"
+	clickhouseNativeDriverResultHashSettingName = "result_hash"
+	clickhouseNativeDriverDefaultResultHash     = true
+)
+
+func NewClickHouseNativeDriver(host string,
+	port int,
+	user string,
+	password string,
+	database string,
+	resultHash bool,
+	settings Settings,
+) (*ClickHouseNativeDriver, error) {
+	options := ch.Options{
+		Address:     net.JoinHostPort(host, strconv.Itoa(port)),
+		User:        user,
+		Password:    password,
+		Database:    database,
+		ClientName:  "paw",
+		ReadTimeout: ch.NoTimeout,
+	}
+
+	querySettings := make([]ch.Setting, 0, len(settings))
+	for name, value := range settings {
+		querySettings = append(querySettings, ch.Setting{Key: name, Value: fmt.Sprintf("%v", value)})
+	}
+
+	c := &ClickHouseNativeDriver{
+		Host:       host,
+		Port:       port,
+		options:    options,
+		settings:   querySettings,
+		resultHash: resultHash,
+	}
+	return c, nil
+}
+
+func (c *ClickHouseNativeDriver) Run(ctx context.Context, command string) (ExecutionTime, error) {
+	if c.client == nil || c.client.IsClosed() {
+		client, err := ch.Dial(ctx, c.options)
+		if err != nil {
+			return ExecutionTime{}, fmt.Errorf("%s driver connect error: %w", clickhouseNativeDriverName, err)
+		}
+
+		c.client = client
+	}
+
+	// Columns of result blocks depend on command, so blocks of previous command are not reused
+	if command != c.resultCommand {
+		c.result.blocks = nil
+		c.resultCommand = command
+	}
+
+	c.result.reset(c.resultHash)
+
+	// Progress packets contain increments since previous packet, server elapsed time is their sum
+	var progress proto.Progress
+	var profile proto.Profile
+
+	query := ch.Query{
+		Body:     command,
+		Settings: c.settings,
+		Result:   &c.result,
+		OnResult: func(_ context.Context, _ proto.Block) error {
+			return nil
+		},
+		OnProgress: func(_ context.Context, p proto.Progress) error {
+			progress.Rows += p.Rows
+			progress.Bytes += p.Bytes
+			progress.TotalRows += p.TotalRows
+			progress.WroteRows += p.WroteRows
+			progress.WroteBytes += p.WroteBytes
+			progress.ElapsedNs += p.ElapsedNs
+			return nil
+		},
+		OnProfile: func(_ context.Context, p proto.Profile) error {
+			profile = p
+			return nil
+		},
+	}
+
+	queryStartTime := time.Now()
+
+	err := c.client.Do(ctx, query)
+	if err != nil {
+		c.result.blocks = nil
+		return ExecutionTime{}, fmt.Errorf("%s driver query error: %w", clickhouseNativeDriverName, err)
+	}
+
+	clientDuration := time.Since(queryStartTime)
+	serverDuration := time.Duration(progress.ElapsedNs) * time.Nanosecond
+
+	metrics := map[string]uint64{
+		"read_rows":    progress.Rows,
+		"read_bytes":   progress.Bytes,
+		"result_rows":  profile.Rows,
+		"result_bytes": profile.Bytes,
+	}
+
+	executionTime := ExecutionTime{
+		ClientDuration: clientDuration,
+		ServerDuration: serverDuration,
+		Metrics:        metrics,
+		ResultRows:     c.result.rows,
+	}
+
+	// Result is hashed after client duration is measured
+	if c.resultHash {
+		executionTime.ResultHash = c.result.resultHash()
+	}
+
+	return executionTime, nil
+}
+
+// clickHouseNativeResult decodes each result block into its own columns, so result can be hashed after query is
+// finished. If result is not hashed, all blocks are decoded into the first block columns.
+type clickHouseNativeResult struct {
+	blocks      []proto.Results
+	blocksCount int
+	keepBlocks  bool
+	rows        uint64
+}
+
+func (r *clickHouseNativeResult) reset(keepBlocks bool) {
+	r.blocksCount = 0
+	r.keepBlocks = keepBlocks
+	r.rows = 0
+}
+
+func (r *clickHouseNativeResult) DecodeResult(reader *proto.Reader, version int, block proto.Block) error {
+	// Server sends block without rows with column names and types before data blocks
+	if block.Rows == 0 {
+		var header proto.Results
+		return header.DecodeResult(reader, version, block)
+	}
+
+	if r.blocksCount == len(r.blocks) {
+		r.blocks = append(r.blocks, proto.Results{})
+	}
+
+	err := r.blocks[r.blocksCount].Auto().DecodeResult(reader, version, block)
+	if err != nil {
+		return err
+	}
+
+	r.rows += uint64(block.Rows)
+	if r.keepBlocks {
+		r.blocksCount++
+	}
+
+	return nil
+}
+
+// resultHash returns hash of decoded result blocks. Result columns are generic proto.ColumnOf[T] types, so row values
+// are read using their Row method and formatted as text, columns without Row method are not hashed.
+func (r *clickHouseNativeResult) resultHash() string {
+	var hasher resultHasher
+
+	for _, block := range r.blocks[:r.blocksCount] {
+		rowMethods := []reflect.Value{}
+		for _, column := range block {
+			if rowMethod := reflect.ValueOf(column.Data).MethodByName("Row"); rowMethod.IsValid() {
+				rowMethods = append(rowMethods, rowMethod)
+			}
+		}
+
+		values := make([][]byte, len(rowMethods))
+		rowArguments := make([]reflect.Value, 1)
+
+		for row := range block.Rows() {
+			rowArguments[0] = reflect.ValueOf(row)
+			for i, rowMethod := range rowMethods {
+				values[i] = fmt.Append(values[i][:0], rowMethod.Call(rowArguments)[0].Interface())
+			}
+
+			hasher.addRow(values...)
+		}
+	}
+
+	return hasher.resultHash()
+}
+
+func init() {
+	RegisterDriver(clickhouseNativeDriverName, func(settings Settings) (Driver, error) {
+		host := clickhouseNativeDriverDefaultHost
+		port := clickhouseNativeDriverDefaultPort
+		user := ""
+		password := ""
+		database := ""
+		resultHash := clickhouseNativeDriverDefaultResultHash
+
+		driverSettings := Settings{}
+
+		for name, value := range settings {
+			var ok bool
+
+			switch name {
+			case clickhouseNativeDriverHostSettingName:
+				host, ok = value.(string)
+			case clickhouseNativeDriverPortSettingName:
+				port, ok = value.(int)
+			case clickhouseNativeDriverUserSettingName:
+				user, ok = value.(string)
+			case clickhouseNativeDriverPasswordSettingName:
+				password, ok = value.(string)
+			case clickhouseNativeDriverDatabaseSettingName:
+				database, ok = value.(string)
+			case clickhouseNativeDriverResultHashSettingName:
+				resultHash, ok = value.(bool)
+			default:
+				driverSettings[name] = value
+				continue
+			}
+
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' has invalid type %T",
+					clickhouseNativeDriverName,
+					name,
+					value,
+				)
+			}
+		}
+
+		return NewClickHouseNativeDriver(host, port, user, password, database, resultHash, driverSettings)
+	})
+}
@@ -0,0 +1,261 @@
+package driver_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/stretchr/testify/require"
+)
+
+type clickHouseNativeStandIn struct {
+	port    int
+	logins  chan string
+	queries chan proto.Query
+}
+
+// runClickHouseNativeStandIn accepts connections and answers every query with two progress packets, profile and
+// the same two rows in alternating order, either in one block or in two blocks. Queries containing 'error' fail with
+// server exception.
+func runClickHouseNativeStandIn(t *testing.T) *clickHouseNativeStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	standIn := &clickHouseNativeStandIn{
+		port:    listener.Addr().(*net.TCPAddr).Port,
+		logins:  make(chan string, 16),
+		queries: make(chan proto.Query, 16),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go standIn.serve(conn)
+		}
+	}()
+
+	return standIn
+}
+
+func (s *clickHouseNativeStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := proto.NewReader(conn)
+	buffer := new(proto.Buffer)
+	flush := func() error {
+		_, err := conn.Write(buffer.Buf)
+		buffer.Reset()
+		return err
+	}
+
+	code, err := reader.UVarInt()
+	if err != nil || proto.ClientCode(code) != proto.ClientCodeHello {
+		return
+	}
+
+	var hello proto.ClientHello
+	if err := hello.Decode(reader); err != nil {
+		return
+	}
+
+	version := hello.ProtocolVersion
+	serverHello := proto.ServerHello{Name: "ClickHouse", Major: 24, Minor: 1, Revision: version}
+	serverHello.EncodeAware(buffer, version)
+	if err := flush(); err != nil {
+		return
+	}
+
+	if proto.FeatureAddendum.In(version) {
+		if _, err := reader.Str(); err != nil {
+			return
+		}
+	}
+
+	s.logins <- hello.User + ":" + hello.Password + "@" + hello.Database
+
+	for reversed := false; ; reversed = !reversed {
+		code, err := reader.UVarInt()
+		if err != nil || proto.ClientCode(code) != proto.ClientCodeQuery {
+			return
+		}
+
+		var query proto.Query
+		if err := query.DecodeAware(reader, version); err != nil {
+			return
+		}
+
+		// Query is followed by blank block that marks end of external data
+		var clientData proto.ClientData
+		var block proto.Block
+		if _, err := reader.UVarInt(); err != nil {
+			return
+		}
+		if err := clientData.DecodeAware(reader, version); err != nil {
+			return
+		}
+		if err := block.DecodeBlock(reader, version, nil); err != nil {
+			return
+		}
+
+		s.queries <- query
+
+		if strings.Contains(query.Body, "error") {
+			proto.ServerCodeException.Encode(buffer)
+			exception := proto.Exception{Code: 62, Name: "DB::Exception", Message: "Syntax error"}
+			exception.EncodeAware(buffer, version)
+		} else if err := encodeClickHouseNativeResult(buffer, version, reversed); err != nil {
+			return
+		}
+
+		if err := flush(); err != nil {
+			return
+		}
+	}
+}
+
+func encodeClickHouseNativeResult(buffer *proto.Buffer, version int, reversed bool) error {
+	for _, progress := range []proto.Progress{
+		{Rows: 1, Bytes: 8, TotalRows: 2, ElapsedNs: 5_000_000},
+		{Rows: 1, Bytes: 8, ElapsedNs: 7_750_000},
+	} {
+		proto.ServerCodeProgress.Encode(buffer)
+		progress.EncodeAware(buffer, version)
+	}
+
+	getColumns := func(numbers proto.ColUInt64, names ...proto.Nullable[string]) []proto.InputColumn {
+		nameColumn := new(proto.ColStr).Nullable()
+		nameColumn.AppendArr(names)
+
+		return []proto.InputColumn{{Name: "number", Data: numbers}, {Name: "name", Data: nameColumn}}
+	}
+
+	// Header block without rows is sent before data, same as ClickHouse server does
+	blocks := [][]proto.InputColumn{
+		getColumns(proto.ColUInt64{}),
+		getColumns(proto.ColUInt64{1, 2}, proto.NewNullable("a"), proto.Null[string]()),
+	}
+	if reversed {
+		blocks = [][]proto.InputColumn{
+			blocks[0],
+			getColumns(proto.ColUInt64{2}, proto.Null[string]()),
+			getColumns(proto.ColUInt64{1}, proto.NewNullable("a")),
+		}
+	}
+
+	for _, columns := range blocks {
+		proto.ServerCodeData.Encode(buffer)
+		proto.ClientData{}.EncodeAware(buffer, version)
+
+		block := proto.Block{Columns: len(columns), Rows: columns[0].Data.Rows(), Info: proto.BlockInfo{BucketNum: -1}}
+		if err := block.EncodeBlock(buffer, version, columns); err != nil {
+			return err
+		}
+	}
+
+	proto.Profile{Rows: 2, Blocks: 1, Bytes: 16}.EncodeAware(buffer, version)
+	proto.ServerCodeEndOfStream.Encode(buffer)
+
+	return nil
+}
+
+func TestClickHouseNativeDriver(t *testing.T) {
+	standIn := runClickHouseNativeStandIn(t)
+
+	drv, err := driver.CreateDriver("clickhouse_native", driver.Settings{
+		"port":        standIn.port,
+		"user":        "paw",
+		"password":    "secret",
+		"database":    "test",
+		"max_threads": 16,
+	})
+	require.NoError(t, err)
+
+	var resultHashes []string
+	for range 2 {
+		executionTime, err := drv.Run(context.Background(), "SELECT number, name FROM t")
+		require.NoError(t, err)
+		require.Equal(t, 12750*time.Microsecond, executionTime.ServerDuration)
+		require.Positive(t, executionTime.ClientDuration)
+		require.Equal(t, map[string]uint64{
+			"read_rows":    2,
+			"read_bytes":   16,
+			"result_rows":  2,
+			"result_bytes": 16,
+		}, executionTime.Metrics)
+		require.Equal(t, uint64(2), executionTime.ResultRows)
+		require.NotEmpty(t, executionTime.ResultHash)
+		resultHashes = append(resultHashes, executionTime.ResultHash)
+
+		query := <-standIn.queries
+		require.Equal(t, "SELECT number, name FROM t", query.Body)
+		require.Contains(t, query.Settings, proto.Setting{Key: "max_threads", Value: "16"})
+	}
+
+	// Rows are returned in different order and in different blocks, result hash must not change
+	require.Equal(t, resultHashes[0], resultHashes[1])
+
+	// Connection is reused between runs
+	require.Equal(t, "paw:secret@test", <-standIn.logins)
+	require.Empty(t, standIn.logins)
+}
+
+func TestClickHouseNativeDriverWithoutResultHash(t *testing.T) {
+	standIn := runClickHouseNativeStandIn(t)
+
+	drv, err := driver.CreateDriver("clickhouse_native", driver.Settings{"port": standIn.port, "result_hash": false})
+	require.NoError(t, err)
+
+	for range 2 {
+		executionTime, err := drv.Run(context.Background(), "SELECT number, name FROM t")
+		require.NoError(t, err)
+		require.Empty(t, executionTime.ResultHash)
+		require.Equal(t, uint64(2), executionTime.ResultRows)
+
+		query := <-standIn.queries
+		require.NotContains(t, query.Settings, proto.Setting{Key: "result_hash", Value: "false"})
+	}
+}
+
+func TestClickHouseNativeDriverQueryError(t *testing.T) {
+	standIn := runClickHouseNativeStandIn(t)
+
+	drv, err := driver.CreateDriver("clickhouse_native", driver.Settings{"port": standIn.port})
+	require.NoError(t, err)
+
+	_, err = drv.Run(context.Background(), "SELECT error")
+	require.ErrorContains(t, err, "clickhouse_native driver query error")
+	require.ErrorContains(t, err, "Syntax error")
+}
+
+func TestClickHouseNativeDriverConnectError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	drv, err := driver.CreateDriver("clickhouse_native", driver.Settings{"port": port})
+	require.NoError(t, err)
+
+	_, err = drv.Run(context.Background(), "SELECT 1")
+	require.ErrorContains(t, err, "clickhouse_native driver connect error")
+}
+
+func TestClickHouseNativeDriverInvalidSetting(t *testing.T) {
+	_, err := driver.CreateDriver("clickhouse_native", driver.Settings{"port": "9000"})
+	require.ErrorContains(t, err, "clickhouse_native driver profile setting 'port' has invalid type string")
+
+	_, err = driver.CreateDriver("clickhouse_native", driver.Settings{"user": 1})
+	require.ErrorContains(t, err, "clickhouse_native driver profile setting 'user' has invalid type int")
+
+	_, err = driver.CreateDriver("clickhouse_native", driver.Settings{"result_hash": "false"})
+	require.ErrorContains(t, err, "clickhouse_native driver profile setting 'result_hash' has invalid type string")
+}
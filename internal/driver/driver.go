@@ -9,6 +9,11 @@ import (
 type ExecutionTime struct {
 	ClientDuration time.Duration `json:"client_duration"`
 	ServerDuration time.Duration `json:"server_duration"`
+	// Metrics contains driver specific per run metrics, for example read rows or memory usage
+	Metrics map[string]uint64 `json:"metrics,omitempty"`
+	// ResultHash is order independent hash of query result rows, empty if driver does not support result hashing
+	ResultHash string `json:"result_hash,omitempty"`
+	ResultRows uint64 `json:"result_rows,omitempty"`
 }
 
 type Settings = map[string]any
@@ -0,0 +1,265 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type ExecDriver struct {
+	Command         []string
+	serverTimeRegex *regexp.Regexp
+	serverTimeUnit  time.Duration
+	resultHash      bool
+}
+
+const (
+	execDriverName                          = "exec"
+	execDriverCommandSettingName            = "command"
+	execDriverServerTimeRegexSettingName    = "server_time_regex"
+	execDriverDefaultServerTimeUnit         = "s"
+	execDriverServerTimeUnitSettingName     = "server_time_unit"
+	execDriverResultHashSettingName         = "result_hash"
+	execDriverDefaultResultHash             = true
+	execDriverQueryPlaceholder              = "{{query}}"
+	execDriverShellCommandInterpreter       = "sh"
+	execDriverShellCommandInterpreterOption = "-c"
+)
+
+var execDriverServerTimeUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+// NewExecDriver creates driver that runs command for each query, every {{query}} placeholder in command
+// arguments is replaced with query text. If server time regex is specified, its first capture group is
+// parsed as number of server time units from command stdout and stderr, all matches are summed. If result hash
+// is enabled, stdout lines are hashed, except lines that match server time regex.
+func NewExecDriver(command []string,
+	serverTimeRegex *regexp.Regexp,
+	serverTimeUnit time.Duration,
+	resultHash bool,
+) (*ExecDriver, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("%s driver command is empty", execDriverName)
+	}
+
+	if serverTimeRegex != nil && serverTimeRegex.NumSubexp() < 1 {
+		return nil, fmt.Errorf("%s driver server time regex '%s' must have capture group",
+			execDriverName,
+			serverTimeRegex.String(),
+		)
+	}
+
+	e := &ExecDriver{
+		Command:         command,
+		serverTimeRegex: serverTimeRegex,
+		serverTimeUnit:  serverTimeUnit,
+		resultHash:      resultHash,
+	}
+	return e, nil
+}
+
+func (e *ExecDriver) Run(ctx context.Context, command string) (ExecutionTime, error) {
+	args := make([]string, len(e.Command))
+	for i, arg := range e.Command {
+		args[i] = strings.ReplaceAll(arg, execDriverQueryPlaceholder, command)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	// Command is run in its own process group, so processes started by shell are also killed when ctx is done
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	queryStartTime := time.Now()
+
+	err := cmd.Run()
+	if err != nil {
+		return ExecutionTime{}, fmt.Errorf("%s driver command %v error: %w: %s",
+			execDriverName,
+			e.Command,
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	clientDuration := time.Since(queryStartTime)
+
+	var serverDuration time.Duration
+	if e.serverTimeRegex != nil {
+		for _, output := range []string{stdout.String(), stderr.String()} {
+			for _, match := range e.serverTimeRegex.FindAllStringSubmatch(output, -1) {
+				value, err := strconv.ParseFloat(match[1], 64)
+				if err != nil {
+					return ExecutionTime{}, fmt.Errorf("%s driver server time '%s' parse error: %w",
+						execDriverName,
+						match[1],
+						err,
+					)
+				}
+
+				serverDuration += time.Duration(value * float64(e.serverTimeUnit))
+			}
+		}
+	}
+
+	executionTime := ExecutionTime{ClientDuration: clientDuration, ServerDuration: serverDuration}
+
+	if e.resultHash {
+		var hasher resultHasher
+		for _, line := range bytes.Split(stdout.Bytes(), []byte("\n")) {
+			// Server time differs between runs, so lines that report it are not part of result
+			if len(line) == 0 || (e.serverTimeRegex != nil && e.serverTimeRegex.Match(line)) {
+				continue
+			}
+
+			hasher.addRow(line)
+		}
+
+		executionTime.ResultHash = hasher.resultHash()
+		executionTime.ResultRows = hasher.rows
+	}
+
+	return executionTime, nil
+}
+
+// getExecDriverShellCommand returns shell command in which every {{query}} placeholder is replaced with query
+// positional parameter. Parameter is quoted according to placeholder position, so query is passed as is if
+// placeholder is not quoted, is inside double quotes or is inside single quotes.
+func getExecDriverShellCommand(command string) string {
+	var builder strings.Builder
+	var quote byte
+
+	for i := 0; i < len(command); i++ {
+		if strings.HasPrefix(command[i:], execDriverQueryPlaceholder) {
+			switch quote {
+			case '\'':
+				// Parameter can not be expanded inside single quotes, so single quotes are closed around it
+				builder.WriteString(`'"$1"'`)
+			case '"':
+				builder.WriteString(`$1`)
+			default:
+				builder.WriteString(`"$1"`)
+			}
+
+			i += len(execDriverQueryPlaceholder) - 1
+			continue
+		}
+
+		c := command[i]
+		builder.WriteByte(c)
+
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(command):
+			i++
+			builder.WriteByte(command[i])
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case quote == c:
+			quote = 0
+		}
+	}
+
+	return builder.String()
+}
+
+func init() {
+	RegisterDriver(execDriverName, func(settings Settings) (Driver, error) {
+		var command []string
+
+		switch commandValue := settings[execDriverCommandSettingName].(type) {
+		case string:
+			// Command specified as string is run using shell, query is passed as positional parameter
+			// so it does not require any quoting inside command
+			command = []string{
+				execDriverShellCommandInterpreter,
+				execDriverShellCommandInterpreterOption,
+				getExecDriverShellCommand(commandValue),
+				execDriverName,
+				execDriverQueryPlaceholder,
+			}
+		case []any:
+			for _, argAny := range commandValue {
+				command = append(command, fmt.Sprintf("%v", argAny))
+			}
+		case nil:
+			return nil, fmt.Errorf("%s driver profile setting '%s' is required",
+				execDriverName,
+				execDriverCommandSettingName,
+			)
+		default:
+			return nil, fmt.Errorf("%s driver profile setting '%s' is not string or list",
+				execDriverName,
+				execDriverCommandSettingName,
+			)
+		}
+
+		var serverTimeRegex *regexp.Regexp
+		if serverTimeRegexAny, ok := settings[execDriverServerTimeRegexSettingName]; ok {
+			serverTimeRegexString, ok := serverTimeRegexAny.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is not string",
+					execDriverName,
+					execDriverServerTimeRegexSettingName,
+				)
+			}
+
+			var err error
+			serverTimeRegex, err = regexp.Compile(serverTimeRegexString)
+			if err != nil {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is invalid regex: %w",
+					execDriverName,
+					execDriverServerTimeRegexSettingName,
+					err,
+				)
+			}
+		}
+
+		serverTimeUnitName := execDriverDefaultServerTimeUnit
+		if serverTimeUnitAny, ok := settings[execDriverServerTimeUnitSettingName]; ok {
+			serverTimeUnitName, ok = serverTimeUnitAny.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is not string",
+					execDriverName,
+					execDriverServerTimeUnitSettingName,
+				)
+			}
+		}
+
+		serverTimeUnit, ok := execDriverServerTimeUnits[serverTimeUnitName]
+		if !ok {
+			return nil, fmt.Errorf("%s driver profile setting '%s' has unknown unit '%s'",
+				execDriverName,
+				execDriverServerTimeUnitSettingName,
+				serverTimeUnitName,
+			)
+		}
+
+		resultHash := execDriverDefaultResultHash
+		if resultHashAny, ok := settings[execDriverResultHashSettingName]; ok {
+			resultHash, ok = resultHashAny.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' is not bool",
+					execDriverName,
+					execDriverResultHashSettingName,
+				)
+			}
+		}
+
+		return NewExecDriver(command, serverTimeRegex, serverTimeUnit, resultHash)
+	})
+}
@@ -0,0 +1,101 @@
+package driver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecDriverShellCommand(t *testing.T) {
+	drv, err := driver.CreateDriver("exec", driver.Settings{
+		"command":           `printf '%s\n' {{query}}; echo 'Elapsed: 0.25 ms' >&2; echo 'Elapsed: 0.5 ms' >&2`,
+		"server_time_regex": `Elapsed: ([0-9.]+) ms`,
+		"server_time_unit":  "ms",
+	})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT 'quoted \"text\"'")
+	require.NoError(t, err)
+	require.Equal(t, 750*time.Microsecond, executionTime.ServerDuration)
+	require.Positive(t, executionTime.ClientDuration)
+}
+
+func TestExecDriverShellCommandQuotedPlaceholder(t *testing.T) {
+	for _, command := range []string{
+		`test {{query}} = "SELECT 'a' || \"b\""`,
+		`test "{{query}}" = "SELECT 'a' || \"b\""`,
+		`test '{{query}}' = "SELECT 'a' || \"b\""`,
+		`test 'prefix {{query}}' = "prefix SELECT 'a' || \"b\""`,
+	} {
+		drv, err := driver.CreateDriver("exec", driver.Settings{"command": command})
+		require.NoError(t, err)
+
+		_, err = drv.Run(context.Background(), `SELECT 'a' || "b"`)
+		require.NoError(t, err, command)
+	}
+}
+
+func TestExecDriverResultHash(t *testing.T) {
+	drv, err := driver.CreateDriver("exec", driver.Settings{
+		"command":           `printf '%s\n' 1 2 "Elapsed: $(date +%N) ns"`,
+		"server_time_regex": `Elapsed: ([0-9]+) ns`,
+		"server_time_unit":  "ns",
+	})
+	require.NoError(t, err)
+
+	lhsExecutionTime, err := drv.Run(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+
+	rhsExecutionTime, err := drv.Run(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+
+	// Server time line is not part of result
+	require.Equal(t, uint64(2), lhsExecutionTime.ResultRows)
+	require.NotEmpty(t, lhsExecutionTime.ResultHash)
+	require.Equal(t, lhsExecutionTime.ResultHash, rhsExecutionTime.ResultHash)
+	require.NotEqual(t, lhsExecutionTime.ServerDuration, rhsExecutionTime.ServerDuration)
+}
+
+func TestExecDriverWithoutResultHash(t *testing.T) {
+	drv, err := driver.CreateDriver("exec", driver.Settings{
+		"command":     `printf '%s\n' 1 2`,
+		"result_hash": false,
+	})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.Empty(t, executionTime.ResultHash)
+	require.Zero(t, executionTime.ResultRows)
+}
+
+func TestExecDriverArgumentsCommand(t *testing.T) {
+	drv, err := driver.CreateDriver("exec", driver.Settings{
+		"command": []any{"sh", "-c", `test "$1" = "SELECT 1"`, "sh", "{{query}}"},
+	})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), executionTime.ServerDuration)
+
+	_, err = drv.Run(context.Background(), "SELECT 2")
+	require.Error(t, err)
+}
+
+func TestExecDriverInvalidSettings(t *testing.T) {
+	_, err := driver.CreateDriver("exec", driver.Settings{})
+	require.Error(t, err)
+
+	_, err = driver.CreateDriver("exec", driver.Settings{"command": "true", "server_time_regex": "[0-9]+"})
+	require.Error(t, err)
+
+	_, err = driver.CreateDriver("exec", driver.Settings{"command": "true", "server_time_unit": "h"})
+	require.Error(t, err)
+
+	_, err = driver.CreateDriver("exec", driver.Settings{"command": "true", "result_hash": "false"})
+	require.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type MySQLDriver struct {
+	Host              string
+	Port              int
+	performanceSchema bool
+	db                *sql.DB
+	conn              *sql.Conn
+	threadID          uint64
+}
+
+const (
+	mysqlDriverName                         = "mysql"
+	mysqlDriverDefaultHost                  = "127.0.0.1"
+	mysqlDriverHostSettingName              = "host"
+	mysqlDriverDefaultPort                  = 3306
+	mysqlDriverPortSettingName              = "port"
+	mysqlDriverDefaultUser                  = "root"
+	mysqlDriverUserSettingName              = "user"
+	mysqlDriverPasswordSettingName          = "password"
+	mysqlDriverDatabaseSettingName          = "database"
+	mysqlDriverDefaultPerformanceSchema     = true
+	mysqlDriverPerformanceSchemaSettingName = "performance_schema"
+)
+
+func NewMySQLDriver(host string,
+	port int,
+	user string,
+	password string,
+	database string,
+	performanceSchema bool,
+	settings Settings,
+) (*MySQLDriver, error) {
+	config := mysql.NewConfig()
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+	config.User = user
+	config.Passwd = password
+	config.DBName = database
+	config.Params = map[string]string{}
+
+	// Remaining profile settings are set as session variables after connect, similar to
+	// ClickHouse driver that forwards them as query settings
+	for name, value := range settings {
+		if stringValue, ok := value.(string); ok {
+			config.Params[name] = "'" + strings.ReplaceAll(stringValue, "'", "''") + "'"
+		} else {
+			config.Params[name] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	connector, err := mysql.NewConnector(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s driver config error: %w", mysqlDriverName, err)
+	}
+
+	m := &MySQLDriver{
+		Host:              host,
+		Port:              port,
+		performanceSchema: performanceSchema,
+		db:                sql.OpenDB(connector),
+	}
+	return m, nil
+}
+
+func (m *MySQLDriver) Run(ctx context.Context, command string) (ExecutionTime, error) {
+	if err := m.connect(ctx); err != nil {
+		return ExecutionTime{}, err
+	}
+
+	queryStartTime := time.Now()
+
+	rows, err := m.conn.QueryContext(ctx, command)
+	if err != nil {
+		m.resetConnectionOnError(err)
+		return ExecutionTime{}, fmt.Errorf("%s driver query error: %w", mysqlDriverName, err)
+	}
+
+	// Read all rows to ensure query completes
+	var hasher resultHasher
+	err = readAllRows(rows, &hasher)
+	if err != nil {
+		m.resetConnectionOnError(err)
+		return ExecutionTime{}, fmt.Errorf("%s driver response read error: %w", mysqlDriverName, err)
+	}
+
+	clientDuration := time.Since(queryStartTime)
+
+	var serverDuration time.Duration
+	if m.performanceSchema {
+		serverDuration, err = m.lastStatementDuration(ctx)
+		if err != nil {
+			return ExecutionTime{}, fmt.Errorf("%s driver performance_schema read error: %w", mysqlDriverName, err)
+		}
+	}
+
+	return ExecutionTime{
+		ClientDuration: clientDuration,
+		ServerDuration: serverDuration,
+		ResultHash:     hasher.resultHash(),
+		ResultRows:     hasher.rows,
+	}, nil
+}
+
+// connect pins single connection, statements history in performance_schema is tracked per thread,
+// so query and its timing lookup must run in the same session.
+func (m *MySQLDriver) connect(ctx context.Context) error {
+	if m.conn != nil {
+		return nil
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%s driver connect error: %w", mysqlDriverName, err)
+	}
+
+	if m.performanceSchema {
+		err = conn.QueryRowContext(ctx,
+			"SELECT THREAD_ID FROM performance_schema.threads WHERE PROCESSLIST_ID = CONNECTION_ID()",
+		).Scan(&m.threadID)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("%s driver performance_schema thread id read error: %w", mysqlDriverName, err)
+		}
+	}
+
+	m.conn = conn
+	return nil
+}
+
+func (m *MySQLDriver) resetConnectionOnError(err error) {
+	if err == nil || m.conn == nil {
+		return
+	}
+
+	// Server side errors keep connection usable, any other error can leave it in broken state
+	var mysqlError *mysql.MySQLError
+	if errors.As(err, &mysqlError) {
+		return
+	}
+
+	m.conn.Close()
+	m.conn = nil
+}
+
+// lastStatementDuration returns duration of the last completed statement in the driver session.
+// The lookup statement itself is still running, so it is not present in history yet.
+func (m *MySQLDriver) lastStatementDuration(ctx context.Context) (time.Duration, error) {
+	query := fmt.Sprintf(
+		"SELECT TIMER_WAIT FROM performance_schema.events_statements_history "+
+			"WHERE THREAD_ID = %d ORDER BY EVENT_ID DESC LIMIT 1",
+		m.threadID,
+	)
+
+	var timerWaitPicoseconds uint64
+	if err := m.conn.QueryRowContext(ctx, query).Scan(&timerWaitPicoseconds); err != nil {
+		return 0, err
+	}
+
+	return time.Duration(timerWaitPicoseconds/1000) * time.Nanosecond, nil
+}
+
+func readAllRows(rows *sql.Rows, hasher *resultHasher) error {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := make([][]byte, len(columns))
+	valuePointers := make([]any, len(columns))
+	for i := range values {
+		valuePointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(valuePointers...); err != nil {
+			return err
+		}
+
+		hasher.addRow(values...)
+	}
+
+	return rows.Err()
+}
+
+func init() {
+	RegisterDriver(mysqlDriverName, func(settings Settings) (Driver, error) {
+		host := mysqlDriverDefaultHost
+		port := mysqlDriverDefaultPort
+		user := mysqlDriverDefaultUser
+		password := ""
+		database := ""
+		performanceSchema := mysqlDriverDefaultPerformanceSchema
+
+		driverSettings := Settings{}
+
+		for name, value := range settings {
+			var ok bool
+
+			switch name {
+			case mysqlDriverHostSettingName:
+				host, ok = value.(string)
+			case mysqlDriverPortSettingName:
+				port, ok = value.(int)
+			case mysqlDriverUserSettingName:
+				user, ok = value.(string)
+			case mysqlDriverPasswordSettingName:
+				password, ok = value.(string)
+			case mysqlDriverDatabaseSettingName:
+				database, ok = value.(string)
+			case mysqlDriverPerformanceSchemaSettingName:
+				performanceSchema, ok = value.(bool)
+			default:
+				driverSettings[name] = value
+				continue
+			}
+
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' has invalid type %T",
+					mysqlDriverName,
+					name,
+					value,
+				)
+			}
+		}
+
+		return NewMySQLDriver(host, port, user, password, database, performanceSchema, driverSettings)
+	})
+}
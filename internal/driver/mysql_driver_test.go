@@ -0,0 +1,321 @@
+package driver_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mysqlTypeLong     = 0x03
+	mysqlTypeLongLong = 0x08
+	mysqlTypeString   = 0xfd
+	mysqlFlagUnsigned = 0x20
+)
+
+type mysqlStandInColumn struct {
+	name      string
+	fieldType byte
+	flags     uint16
+}
+
+// mysqlStandInConn writes MySQL protocol packets, sequence id is reset by every received command.
+type mysqlStandInConn struct {
+	reader   *bufio.Reader
+	writer   io.Writer
+	sequence byte
+}
+
+func (c *mysqlStandInConn) readPacket() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return nil, err
+	}
+
+	c.sequence = header[3] + 1
+	return payload, nil
+}
+
+func (c *mysqlStandInConn) writePacket(payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), c.sequence}
+	c.sequence++
+
+	_, err := c.writer.Write(append(header, payload...))
+	return err
+}
+
+func (c *mysqlStandInConn) writeOK() error {
+	return c.writePacket([]byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00})
+}
+
+func (c *mysqlStandInConn) writeEOF() error {
+	return c.writePacket([]byte{0xfe, 0x00, 0x00, 0x02, 0x00})
+}
+
+func (c *mysqlStandInConn) writeError(message string) error {
+	payload := []byte{0xff, 0x28, 0x04, '#', '4', '2', '0', '0', '0'}
+	return c.writePacket(append(payload, message...))
+}
+
+// writeResultSet writes text protocol result set, nil value is written as NULL.
+func (c *mysqlStandInConn) writeResultSet(columns []mysqlStandInColumn, rows [][]*string) error {
+	if err := c.writePacket([]byte{byte(len(columns))}); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		var payload []byte
+		for _, value := range []string{"def", "", "", "", column.name, column.name} {
+			payload = appendMySQLString(payload, value)
+		}
+
+		payload = append(payload, 0x0c, 0x21, 0x00, 0x00, 0x01, 0x00, 0x00, column.fieldType)
+		payload = binary.LittleEndian.AppendUint16(payload, column.flags)
+		payload = append(payload, 0x00, 0x00, 0x00)
+
+		if err := c.writePacket(payload); err != nil {
+			return err
+		}
+	}
+
+	if err := c.writeEOF(); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		var payload []byte
+		for _, value := range row {
+			if value == nil {
+				payload = append(payload, 0xfb)
+			} else {
+				payload = appendMySQLString(payload, *value)
+			}
+		}
+
+		if err := c.writePacket(payload); err != nil {
+			return err
+		}
+	}
+
+	return c.writeEOF()
+}
+
+func appendMySQLString(payload []byte, value string) []byte {
+	return append(append(payload, byte(len(value))), value...)
+}
+
+// writeHandshake writes initial handshake with mysql_native_password auth plugin.
+func (c *mysqlStandInConn) writeHandshake() error {
+	payload := []byte{0x0a}
+	payload = append(payload, "8.0.0\x00"...)
+	payload = append(payload, 0x01, 0x00, 0x00, 0x00)
+	payload = append(payload, "abcdefgh\x00"...)
+	// CLIENT_LONG_PASSWORD, CLIENT_CONNECT_WITH_DB, CLIENT_PROTOCOL_41, CLIENT_SECURE_CONNECTION
+	// and CLIENT_PLUGIN_AUTH capabilities
+	payload = append(payload, 0x09, 0x82, 0x21, 0x02, 0x00, 0x08, 0x00, 21)
+	payload = append(payload, make([]byte, 10)...)
+	payload = append(payload, "ijklmnopqrst\x00"...)
+	payload = append(payload, "mysql_native_password\x00"...)
+
+	return c.writePacket(payload)
+}
+
+// parseMySQLHandshakeResponse returns user and database from handshake response.
+func parseMySQLHandshakeResponse(payload []byte) (string, string) {
+	flags := binary.LittleEndian.Uint32(payload)
+	payload = payload[32:]
+
+	user, payload, _ := bytes.Cut(payload, []byte{0x00})
+	payload = payload[1+int(payload[0]):]
+
+	var database []byte
+	if flags&0x08 != 0 {
+		database, _, _ = bytes.Cut(payload, []byte{0x00})
+	}
+
+	return string(user), string(database)
+}
+
+type mysqlStandIn struct {
+	port    int
+	logins  chan string
+	queries chan string
+}
+
+// runMySQLStandIn accepts connections and answers performance_schema lookups with fixed thread id and
+// 12.75ms statement duration, every other SELECT query with the same two rows in alternating order.
+// Queries containing 'error' fail with server error.
+func runMySQLStandIn(t *testing.T) *mysqlStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	standIn := &mysqlStandIn{
+		port:    listener.Addr().(*net.TCPAddr).Port,
+		logins:  make(chan string, 16),
+		queries: make(chan string, 16),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go standIn.serve(conn)
+		}
+	}()
+
+	return standIn
+}
+
+func (s *mysqlStandIn) serve(netConn net.Conn) {
+	defer netConn.Close()
+
+	conn := &mysqlStandInConn{reader: bufio.NewReader(netConn), writer: netConn}
+	if err := conn.writeHandshake(); err != nil {
+		return
+	}
+
+	payload, err := conn.readPacket()
+	if err != nil {
+		return
+	}
+
+	user, database := parseMySQLHandshakeResponse(payload)
+	s.logins <- user + "@" + database
+
+	if err := conn.writeOK(); err != nil {
+		return
+	}
+
+	one, two, name := "1", "2", "a"
+	rows := [][]*string{{&one, &name}, {&two, nil}}
+
+	for {
+		payload, err := conn.readPacket()
+		if err != nil || len(payload) == 0 || payload[0] != 0x03 {
+			return
+		}
+
+		query := string(payload[1:])
+		s.queries <- query
+
+		switch {
+		case strings.HasPrefix(query, "SET "):
+			err = conn.writeOK()
+		case strings.Contains(query, "error"):
+			err = conn.writeError("You have an error in your SQL syntax")
+		case strings.Contains(query, "performance_schema.threads"):
+			threadID := "42"
+			err = conn.writeResultSet([]mysqlStandInColumn{{"THREAD_ID", mysqlTypeLongLong, mysqlFlagUnsigned}},
+				[][]*string{{&threadID}},
+			)
+		case strings.Contains(query, "performance_schema.events_statements_history"):
+			timerWait := "12750000000"
+			err = conn.writeResultSet([]mysqlStandInColumn{{"TIMER_WAIT", mysqlTypeLongLong, mysqlFlagUnsigned}},
+				[][]*string{{&timerWait}},
+			)
+		default:
+			rows[0], rows[1] = rows[1], rows[0]
+			err = conn.writeResultSet([]mysqlStandInColumn{{"id", mysqlTypeLong, 0}, {"name", mysqlTypeString, 0}}, rows)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func TestMySQLDriverPerformanceSchema(t *testing.T) {
+	standIn := runMySQLStandIn(t)
+
+	drv, err := driver.CreateDriver("mysql", driver.Settings{
+		"port":     standIn.port,
+		"user":     "paw",
+		"password": "secret",
+		"database": "test",
+		"sql_mode": "ANSI",
+	})
+	require.NoError(t, err)
+
+	firstExecutionTime, err := drv.Run(context.Background(), "SELECT id, name FROM t")
+	require.NoError(t, err)
+	require.Equal(t, 12750*time.Microsecond, firstExecutionTime.ServerDuration)
+	require.Positive(t, firstExecutionTime.ClientDuration)
+	require.Equal(t, uint64(2), firstExecutionTime.ResultRows)
+	require.NotEmpty(t, firstExecutionTime.ResultHash)
+
+	require.Equal(t, "paw@test", <-standIn.logins)
+	require.Equal(t, "SET sql_mode = 'ANSI'", <-standIn.queries)
+	require.Contains(t, <-standIn.queries, "performance_schema.threads")
+	require.Equal(t, "SELECT id, name FROM t", <-standIn.queries)
+	require.Contains(t, <-standIn.queries, "WHERE THREAD_ID = 42")
+
+	// Rows are returned in different order, result hash must not change
+	secondExecutionTime, err := drv.Run(context.Background(), "SELECT id, name FROM t")
+	require.NoError(t, err)
+	require.Equal(t, firstExecutionTime.ResultHash, secondExecutionTime.ResultHash)
+	require.Equal(t, uint64(2), secondExecutionTime.ResultRows)
+
+	// Connection is reused
+	require.Empty(t, standIn.logins)
+}
+
+func TestMySQLDriverWithoutPerformanceSchema(t *testing.T) {
+	standIn := runMySQLStandIn(t)
+
+	drv, err := driver.CreateDriver("mysql", driver.Settings{"port": standIn.port, "performance_schema": false})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT id, name FROM t")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), executionTime.ServerDuration)
+	require.Equal(t, uint64(2), executionTime.ResultRows)
+
+	require.Equal(t, "root@", <-standIn.logins)
+	require.Equal(t, "SELECT id, name FROM t", <-standIn.queries)
+	require.Empty(t, standIn.queries)
+}
+
+func TestMySQLDriverQueryError(t *testing.T) {
+	standIn := runMySQLStandIn(t)
+
+	drv, err := driver.CreateDriver("mysql", driver.Settings{"port": standIn.port, "performance_schema": false})
+	require.NoError(t, err)
+
+	_, err = drv.Run(context.Background(), "SELECT error")
+	require.ErrorContains(t, err, "mysql driver query error")
+	require.ErrorContains(t, err, "You have an error in your SQL syntax")
+
+	// Server side error keeps connection usable
+	_, err = drv.Run(context.Background(), "SELECT id, name FROM t")
+	require.NoError(t, err)
+	require.Len(t, standIn.logins, 1)
+}
+
+func TestMySQLDriverInvalidSetting(t *testing.T) {
+	_, err := driver.CreateDriver("mysql", driver.Settings{"port": "3306"})
+	require.ErrorContains(t, err, "mysql driver profile setting 'port' has invalid type string")
+
+	_, err = driver.CreateDriver("mysql", driver.Settings{"performance_schema": "true"})
+	require.ErrorContains(t, err, "mysql driver profile setting 'performance_schema' has invalid type string")
+
+	_, err = driver.CreateDriver("mysql", driver.Settings{"user": 1})
+	require.ErrorContains(t, err, "mysql driver profile setting 'user' has invalid type int")
+}
@@ -0,0 +1,200 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PostgresDriver struct {
+	Host string
+	Port int
+	// explainAnalyze is opt-in, because query result is not returned by EXPLAIN ANALYZE, so server duration is
+	// measured only without result hash and result hash only without server duration
+	explainAnalyze bool
+	config         *pgconn.Config
+	conn           *pgconn.PgConn
+}
+
+const (
+	postgresDriverName                      = "postgres"
+	postgresDriverDefaultHost               = "127.0.0.1"
+	postgresDriverHostSettingName           = "host"
+	postgresDriverDefaultPort               = 5432
+	postgresDriverPortSettingName           = "port"
+	postgresDriverDefaultUser               = "postgres"
+	postgresDriverUserSettingName           = "user"
+	postgresDriverPasswordSettingName       = "password"
+	postgresDriverDefaultDatabase           = "postgres"
+	postgresDriverDatabaseSettingName       = "database"
+	postgresDriverDefaultSSLMode            = "disable"
+	postgresDriverSSLModeSettingName        = "sslmode"
+	postgresDriverDefaultExplainAnalyze     = false
+	postgresDriverExplainAnalyzeSettingName = "explain_analyze"
+)
+
+func NewPostgresDriver(host string,
+	port int,
+	user string,
+	password string,
+	database string,
+	sslMode string,
+	explainAnalyze bool,
+	settings Settings,
+) (*PostgresDriver, error) {
+	connURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, password),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+
+	config, err := pgconn.ParseConfig(connURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s driver config parse error: %w", postgresDriverName, err)
+	}
+
+	// Remaining profile settings are applied as session run-time parameters, similar to
+	// ClickHouse driver that forwards them as query settings
+	for name, value := range settings {
+		config.RuntimeParams[name] = fmt.Sprintf("%v", value)
+	}
+
+	p := &PostgresDriver{Host: host, Port: port, explainAnalyze: explainAnalyze, config: config}
+	return p, nil
+}
+
+func (p *PostgresDriver) Run(ctx context.Context, command string) (ExecutionTime, error) {
+	if p.conn == nil || p.conn.IsClosed() {
+		conn, err := pgconn.ConnectConfig(ctx, p.config)
+		if err != nil {
+			return ExecutionTime{}, fmt.Errorf("%s driver connect error: %w", postgresDriverName, err)
+		}
+
+		p.conn = conn
+	}
+
+	statement := command
+	if p.explainAnalyze {
+		statement = fmt.Sprintf("EXPLAIN (ANALYZE, TIMING OFF, FORMAT JSON) %s",
+			strings.TrimRight(strings.TrimSpace(command), ";"),
+		)
+	}
+
+	queryStartTime := time.Now()
+
+	// Read all results to ensure query completes
+	results, err := p.conn.Exec(ctx, statement).ReadAll()
+	if err != nil {
+		return ExecutionTime{}, fmt.Errorf("%s driver query error: %w", postgresDriverName, err)
+	}
+
+	clientDuration := time.Since(queryStartTime)
+
+	if p.explainAnalyze {
+		serverDuration, err := parsePostgresExplainAnalyzeDuration(results)
+		if err != nil {
+			return ExecutionTime{}, fmt.Errorf("%s driver explain analyze parse error: %w", postgresDriverName, err)
+		}
+
+		// Query result is not returned by EXPLAIN ANALYZE, so it can not be hashed
+		return ExecutionTime{ClientDuration: clientDuration, ServerDuration: serverDuration}, nil
+	}
+
+	var hasher resultHasher
+	for _, result := range results {
+		for _, row := range result.Rows {
+			hasher.addRow(row...)
+		}
+	}
+
+	return ExecutionTime{
+		ClientDuration: clientDuration,
+		ResultHash:     hasher.resultHash(),
+		ResultRows:     hasher.rows,
+	}, nil
+}
+
+// parsePostgresExplainAnalyzeDuration returns planning plus execution time reported by
+// EXPLAIN (ANALYZE, FORMAT JSON) output.
+func parsePostgresExplainAnalyzeDuration(results []*pgconn.Result) (time.Duration, error) {
+	if len(results) == 0 {
+		return 0, fmt.Errorf("no results")
+	}
+
+	result := results[len(results)-1]
+	if len(result.Rows) != 1 || len(result.Rows[0]) != 1 {
+		return 0, fmt.Errorf("expected single value result, got %d rows", len(result.Rows))
+	}
+
+	var plans []struct {
+		PlanningTime  float64 `json:"Planning Time"`
+		ExecutionTime float64 `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(result.Rows[0][0], &plans); err != nil {
+		return 0, err
+	}
+
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("empty plan")
+	}
+
+	milliseconds := plans[0].PlanningTime + plans[0].ExecutionTime
+	return time.Duration(milliseconds * float64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterDriver(postgresDriverName, func(settings Settings) (Driver, error) {
+		host := postgresDriverDefaultHost
+		port := postgresDriverDefaultPort
+		user := postgresDriverDefaultUser
+		password := ""
+		database := postgresDriverDefaultDatabase
+		sslMode := postgresDriverDefaultSSLMode
+		explainAnalyze := postgresDriverDefaultExplainAnalyze
+
+		driverSettings := Settings{}
+
+		for name, value := range settings {
+			var ok bool
+
+			switch name {
+			case postgresDriverHostSettingName:
+				host, ok = value.(string)
+			case postgresDriverPortSettingName:
+				port, ok = value.(int)
+			case postgresDriverUserSettingName:
+				user, ok = value.(string)
+			case postgresDriverPasswordSettingName:
+				password, ok = value.(string)
+			case postgresDriverDatabaseSettingName:
+				database, ok = value.(string)
+			case postgresDriverSSLModeSettingName:
+				sslMode, ok = value.(string)
+			case postgresDriverExplainAnalyzeSettingName:
+				explainAnalyze, ok = value.(bool)
+			default:
+				driverSettings[name] = value
+				continue
+			}
+
+			if !ok {
+				return nil, fmt.Errorf("%s driver profile setting '%s' has invalid type %T",
+					postgresDriverName,
+					name,
+					value,
+				)
+			}
+		}
+
+		return NewPostgresDriver(host, port, user, password, database, sslMode, explainAnalyze, driverSettings)
+	})
+}
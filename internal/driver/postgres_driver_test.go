@@ -0,0 +1,102 @@
+package driver_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/kitaisreal/paw/internal/driver"
+	"github.com/stretchr/testify/require"
+)
+
+// runPostgresStandIn accepts single connection and answers every simple query with the given JSON value,
+// received queries are sent to the returned channel.
+func runPostgresStandIn(t *testing.T, value string) (int, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	queries := make(chan string, 16)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		backend := pgproto3.NewBackend(conn, conn)
+		if _, err := backend.ReceiveStartupMessage(); err != nil {
+			return
+		}
+
+		backend.Send(&pgproto3.AuthenticationOk{})
+		backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		if err := backend.Flush(); err != nil {
+			return
+		}
+
+		for {
+			message, err := backend.Receive()
+			if err != nil {
+				return
+			}
+
+			query, ok := message.(*pgproto3.Query)
+			if !ok {
+				return
+			}
+
+			queries <- query.String
+
+			backend.Send(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("QUERY PLAN")}}})
+			backend.Send(&pgproto3.DataRow{Values: [][]byte{[]byte(value)}})
+			backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("EXPLAIN")})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, queries
+}
+
+func TestPostgresDriverExplainAnalyze(t *testing.T) {
+	port, queries := runPostgresStandIn(t, `[{"Plan": {}, "Planning Time": 0.5, "Execution Time": 12.25}]`)
+
+	drv, err := driver.CreateDriver("postgres", driver.Settings{
+		"port":            port,
+		"explain_analyze": true,
+		"work_mem":        "64MB",
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		executionTime, err := drv.Run(context.Background(), "SELECT 1;")
+		require.NoError(t, err)
+		require.Equal(t, 12750*time.Microsecond, executionTime.ServerDuration)
+		require.Positive(t, executionTime.ClientDuration)
+		require.Equal(t, "EXPLAIN (ANALYZE, TIMING OFF, FORMAT JSON) SELECT 1", <-queries)
+	}
+}
+
+func TestPostgresDriverWithoutExplainAnalyze(t *testing.T) {
+	port, queries := runPostgresStandIn(t, "1")
+
+	drv, err := driver.CreateDriver("postgres", driver.Settings{"port": port})
+	require.NoError(t, err)
+
+	executionTime, err := drv.Run(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), executionTime.ServerDuration)
+	require.True(t, strings.HasPrefix(<-queries, "SELECT 1"))
+}
+
+func TestPostgresDriverInvalidSetting(t *testing.T) {
+	_, err := driver.CreateDriver("postgres", driver.Settings{"port": "5432"})
+	require.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+)
+
+// resultHasher computes order independent hash of result rows, each row is hashed separately and row hashes are
+// summed, so queries without ORDER BY that return rows in different order still have same result hash.
+type resultHasher struct {
+	hash uint64
+	rows uint64
+}
+
+func (h *resultHasher) addRow(columns ...[]byte) {
+	rowHash := fnv.New64a()
+	for _, column := range columns {
+		// Length prefix prevents different column splits of same bytes from having same hash
+		_, _ = fmt.Fprintf(rowHash, "%d:", len(column))
+		_, _ = rowHash.Write(column)
+	}
+
+	h.hash += rowHash.Sum64()
+	h.rows++
+}
+
+func (h *resultHasher) addLines(data []byte) {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		h.addRow(line)
+	}
+}
+
+func (h *resultHasher) resultHash() string {
+	return fmt.Sprintf("%016x", h.hash)
+}
@@ -0,0 +1,164 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+)
+
+const (
+	bootstrapResamples  = 2000
+	bootstrapConfidence = 0.95
+)
+
+// ConfidenceInterval is bootstrap percentile confidence interval. Interval is not valid if it can not be computed,
+// for example if there are no values or relative difference to zero median is requested.
+type ConfidenceInterval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Valid bool    `json:"valid"`
+}
+
+// FormatBounds returns interval bounds formatted with the given format in brackets, or "-" if interval is not valid.
+func (c ConfidenceInterval) FormatBounds(format string) string {
+	if !c.Valid {
+		return "-"
+	}
+
+	return fmt.Sprintf("["+format+", "+format+"]", c.Lower, c.Upper)
+}
+
+// GetMedianConfidenceInterval returns bootstrap confidence interval of values median.
+func GetMedianConfidenceInterval(values []float64) ConfidenceInterval {
+	if len(values) == 0 {
+		return ConfidenceInterval{}
+	}
+
+	rng := newBootstrapRand()
+	resample := make([]float64, len(values))
+	medians := make([]float64, bootstrapResamples)
+
+	for i := range medians {
+		medians[i] = getResampleMedian(rng, values, resample)
+	}
+
+	return getPercentileConfidenceInterval(medians)
+}
+
+// GetRelativeMedianConfidenceIntervalWidth returns width of values median bootstrap confidence interval in percents
+// of median.
+func GetRelativeMedianConfidenceIntervalWidth(values []float64) float64 {
+	median := getMedian(values)
+	if median == 0 {
+		return 0
+	}
+
+	confidenceInterval := GetMedianConfidenceInterval(values)
+
+	return (confidenceInterval.Upper - confidenceInterval.Lower) / median * 100
+}
+
+// GetRelativeMedianDiffConfidenceInterval returns bootstrap confidence interval of relative difference
+// (rhs - lhs) / lhs in percents between lhs and rhs medians, lhs and rhs are resampled independently.
+// Interval is not valid if lhs median is zero.
+func GetRelativeMedianDiffConfidenceInterval(lhs []float64, rhs []float64) ConfidenceInterval {
+	if len(lhs) == 0 || len(rhs) == 0 || getMedian(lhs) == 0 {
+		return ConfidenceInterval{}
+	}
+
+	rng := newBootstrapRand()
+	lhsResample := make([]float64, len(lhs))
+	rhsResample := make([]float64, len(rhs))
+	relativeDiffs := make([]float64, bootstrapResamples)
+
+	for i := range relativeDiffs {
+		lhsMedian := getResampleMedian(rng, lhs, lhsResample)
+		rhsMedian := getResampleMedian(rng, rhs, rhsResample)
+
+		// Resample can have zero median even if median of all values is not zero, relative difference is unbounded
+		if lhsMedian == 0 {
+			return ConfidenceInterval{}
+		}
+
+		relativeDiffs[i] = (rhsMedian - lhsMedian) / lhsMedian * 100
+	}
+
+	return getPercentileConfidenceInterval(relativeDiffs)
+}
+
+func (c ConfidenceInterval) toMilliseconds() ConfidenceInterval {
+	return ConfidenceInterval{Lower: c.Lower / 1e6, Upper: c.Upper / 1e6, Valid: c.Valid}
+}
+
+func getDurationValues(times []driver.ExecutionTime,
+	getDuration func(executionTime driver.ExecutionTime) time.Duration,
+) []float64 {
+	values := make([]float64, len(times))
+	for i, t := range times {
+		values[i] = float64(getDuration(t))
+	}
+
+	return values
+}
+
+// Fixed seed makes confidence intervals reproducible, so same results always have same view.
+func newBootstrapRand() *rand.Rand {
+	return rand.New(rand.NewPCG(0x70617720, 0x626f6f74))
+}
+
+func getResampleMedian(rng *rand.Rand, values []float64, resample []float64) float64 {
+	for i := range resample {
+		resample[i] = values[rng.IntN(len(values))]
+	}
+
+	slices.Sort(resample)
+
+	return getSortedMedian(resample)
+}
+
+func getMedian(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sortedValues := slices.Clone(values)
+	slices.Sort(sortedValues)
+
+	return getSortedMedian(sortedValues)
+}
+
+func getSortedMedian(sortedValues []float64) float64 {
+	mid := len(sortedValues) / 2
+	if len(sortedValues)%2 == 0 {
+		return (sortedValues[mid-1] + sortedValues[mid]) / 2
+	}
+
+	return sortedValues[mid]
+}
+
+func getPercentileConfidenceInterval(values []float64) ConfidenceInterval {
+	slices.Sort(values)
+
+	alpha := (1 - bootstrapConfidence) / 2
+
+	return ConfidenceInterval{
+		Lower: getPercentile(values, alpha*100),
+		Upper: getPercentile(values, (1-alpha)*100),
+		Valid: true,
+	}
+}
+
+// getPercentile returns percentile of sorted values using linear interpolation between closest ranks.
+func getPercentile(sortedValues []float64, percentile float64) float64 {
+	rank := percentile / 100 * float64(len(sortedValues)-1)
+	lowerIndex := int(math.Floor(rank))
+	upperIndex := int(math.Ceil(rank))
+
+	lower, upper := sortedValues[lowerIndex], sortedValues[upperIndex]
+
+	return lower + (upper-lower)*(rank-float64(lowerIndex))
+}
@@ -0,0 +1,127 @@
+package stats
+
+import (
+	"slices"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+)
+
+const (
+	histogramBuckets = 20
+
+	// Durations outside of [Q1 - k * IQR, Q3 + k * IQR] are outliers
+	outlierIQRMultiplier = 1.5
+)
+
+// DistributionStats describes shape of durations distribution.
+type DistributionStats struct {
+	P90Duration time.Duration `json:"p90_duration"`
+	P95Duration time.Duration `json:"p95_duration"`
+	P99Duration time.Duration `json:"p99_duration"`
+	// MADDuration is median absolute deviation from median
+	MADDuration time.Duration `json:"mad_duration"`
+	// Outliers contains flag for each execution time, that is true if duration is outside of IQR based fences
+	Outliers  []bool    `json:"outliers"`
+	Histogram Histogram `json:"histogram"`
+}
+
+// Histogram contains number of durations in equal width buckets between min and max duration, bucket i contains
+// durations in [Min + i * BucketWidth, Min + (i + 1) * BucketWidth), last bucket also contains max duration.
+type Histogram struct {
+	Min         time.Duration `json:"min"`
+	BucketWidth time.Duration `json:"bucket_width"`
+	Counts      []uint64      `json:"counts"`
+}
+
+func (s *DistributionStats) GetP90DurationMilliseconds() float64 {
+	return float64(s.P90Duration) / 1e6
+}
+
+func (s *DistributionStats) GetP95DurationMilliseconds() float64 {
+	return float64(s.P95Duration) / 1e6
+}
+
+func (s *DistributionStats) GetP99DurationMilliseconds() float64 {
+	return float64(s.P99Duration) / 1e6
+}
+
+func (s *DistributionStats) GetMADDurationMilliseconds() float64 {
+	return float64(s.MADDuration) / 1e6
+}
+
+func (s *DistributionStats) GetOutliersCount() int {
+	count := 0
+	for _, outlier := range s.Outliers {
+		if outlier {
+			count++
+		}
+	}
+
+	return count
+}
+
+func getDistributionStats(times []driver.ExecutionTime,
+	getDuration func(executionTime driver.ExecutionTime) time.Duration,
+) DistributionStats {
+	result := DistributionStats{}
+
+	if len(times) == 0 {
+		return result
+	}
+
+	durations := make([]time.Duration, len(times))
+	for i, t := range times {
+		durations[i] = getDuration(t)
+	}
+
+	sortedDurations := slices.Clone(durations)
+	slices.Sort(sortedDurations)
+
+	result.P90Duration = getPercentileDuration(sortedDurations, 90)
+	result.P95Duration = getPercentileDuration(sortedDurations, 95)
+	result.P99Duration = getPercentileDuration(sortedDurations, 99)
+
+	median := getPercentileDuration(sortedDurations, 50)
+	absoluteDeviations := make([]time.Duration, len(sortedDurations))
+	for i, duration := range sortedDurations {
+		absoluteDeviations[i] = max(duration-median, median-duration)
+	}
+	slices.Sort(absoluteDeviations)
+	result.MADDuration = getPercentileDuration(absoluteDeviations, 50)
+
+	firstQuartile := getPercentileDuration(sortedDurations, 25)
+	thirdQuartile := getPercentileDuration(sortedDurations, 75)
+	fence := time.Duration(float64(thirdQuartile-firstQuartile) * outlierIQRMultiplier)
+
+	result.Outliers = make([]bool, len(durations))
+	for i, duration := range durations {
+		result.Outliers[i] = duration < firstQuartile-fence || duration > thirdQuartile+fence
+	}
+
+	result.Histogram = getHistogram(sortedDurations)
+
+	return result
+}
+
+func getHistogram(sortedDurations []time.Duration) Histogram {
+	minDuration, maxDuration := sortedDurations[0], sortedDurations[len(sortedDurations)-1]
+
+	// All durations are equal, so there is only one bucket
+	if minDuration == maxDuration {
+		return Histogram{Min: minDuration, Counts: []uint64{uint64(len(sortedDurations))}}
+	}
+
+	histogram := Histogram{
+		Min:         minDuration,
+		BucketWidth: (maxDuration - minDuration + histogramBuckets - 1) / histogramBuckets,
+		Counts:      make([]uint64, histogramBuckets),
+	}
+
+	for _, duration := range sortedDurations {
+		bucket := min(int((duration-minDuration)/histogram.BucketWidth), histogramBuckets-1)
+		histogram.Counts[bucket]++
+	}
+
+	return histogram
+}
@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+)
+
+// Wilcoxon signed-rank test p-value is computed using exact distribution for differences without ties that have at
+// most wilcoxonExactMaxSize non zero values, otherwise normal approximation is used.
+const wilcoxonExactMaxSize = 50
+
+// PairedComparison contains two sided p-values of paired statistical tests for null hypothesis that differences of
+// paired LHS and RHS durations are centered at zero. Durations are paired by index, so they must be measured
+// interleaved. P-value is 1 if there are less than 2 pairs.
+type PairedComparison struct {
+	Pairs                    int     `json:"pairs"`
+	WilcoxonSignedRankPValue float64 `json:"wilcoxon_signed_rank_p_value"`
+	PairedTTestPValue        float64 `json:"paired_t_test_p_value"`
+}
+
+func ComparePairedServerDurations(lhs []driver.ExecutionTime, rhs []driver.ExecutionTime) PairedComparison {
+	return comparePairedDurations(lhs, rhs, getServerDuration)
+}
+
+func ComparePairedClientDurations(lhs []driver.ExecutionTime, rhs []driver.ExecutionTime) PairedComparison {
+	return comparePairedDurations(lhs, rhs, func(t driver.ExecutionTime) time.Duration {
+		return t.ClientDuration
+	})
+}
+
+func comparePairedDurations(lhs []driver.ExecutionTime,
+	rhs []driver.ExecutionTime,
+	getDuration func(executionTime driver.ExecutionTime) time.Duration,
+) PairedComparison {
+	pairs := min(len(lhs), len(rhs))
+
+	lhsValues := getDurationValues(lhs[:pairs], getDuration)
+	rhsValues := getDurationValues(rhs[:pairs], getDuration)
+
+	return PairedComparison{
+		Pairs:                    pairs,
+		WilcoxonSignedRankPValue: WilcoxonSignedRankTest(lhsValues, rhsValues),
+		PairedTTestPValue:        PairedTTest(lhsValues, rhsValues),
+	}
+}
+
+// WilcoxonSignedRankTest returns two sided p-value of Wilcoxon signed-rank test of paired values. Pairs with zero
+// difference are dropped.
+func WilcoxonSignedRankTest(lhs []float64, rhs []float64) float64 {
+	if len(lhs) != len(rhs) || len(lhs) < 2 {
+		return 1
+	}
+
+	differences := []float64{}
+	for i := range lhs {
+		if difference := rhs[i] - lhs[i]; difference != 0 {
+			differences = append(differences, difference)
+		}
+	}
+
+	size := len(differences)
+	if size == 0 {
+		return 1
+	}
+
+	slices.SortFunc(differences, func(a, b float64) int {
+		switch {
+		case math.Abs(a) < math.Abs(b):
+			return -1
+		case math.Abs(a) > math.Abs(b):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	// Tied absolute differences get average of their ranks
+	positiveRankSum := 0.0
+	tiesCorrection := 0.0
+
+	for start := 0; start < size; {
+		end := start + 1
+		for end < size && math.Abs(differences[end]) == math.Abs(differences[start]) {
+			end++
+		}
+
+		averageRank := float64(start+end+1) / 2
+		for _, difference := range differences[start:end] {
+			if difference > 0 {
+				positiveRankSum += averageRank
+			}
+		}
+
+		tiesCount := float64(end - start)
+		tiesCorrection += tiesCount*tiesCount*tiesCount - tiesCount
+
+		start = end
+	}
+
+	if tiesCorrection == 0 && size <= wilcoxonExactMaxSize {
+		return wilcoxonExactPValue(size, int(positiveRankSum))
+	}
+
+	n := float64(size)
+	mean := n * (n + 1) / 4
+	variance := n*(n+1)*(2*n+1)/24 - tiesCorrection/48
+	if variance <= 0 {
+		return 1
+	}
+
+	// Continuity correction
+	z := math.Max(math.Abs(positiveRankSum-mean)-0.5, 0) / math.Sqrt(variance)
+
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// wilcoxonExactPValue returns two sided p-value of signed-rank statistic w using exact distribution of sum of
+// ranks 1..size with random signs.
+func wilcoxonExactPValue(size int, w int) float64 {
+	maxW := size * (size + 1) / 2
+
+	// counts[k] is number of subsets of ranks with sum equal to k
+	counts := make([]float64, maxW+1)
+	counts[0] = 1
+
+	for rank := 1; rank <= size; rank++ {
+		for k := maxW; k >= rank; k-- {
+			counts[k] += counts[k-rank]
+		}
+	}
+
+	// Distribution is symmetric, so tail of smaller of w and maxW - w is used
+	w = min(w, maxW-w)
+
+	tail := 0.0
+	for k := 0; k <= w; k++ {
+		tail += counts[k]
+	}
+
+	return math.Min(1, 2*tail/math.Pow(2, float64(size)))
+}
+
+// PairedTTest returns two sided p-value of paired t-test.
+func PairedTTest(lhs []float64, rhs []float64) float64 {
+	if len(lhs) != len(rhs) || len(lhs) < 2 {
+		return 1
+	}
+
+	differences := make([]float64, len(lhs))
+	for i := range lhs {
+		differences[i] = rhs[i] - lhs[i]
+	}
+
+	mean, variance := getMeanAndSampleVariance(differences)
+	if variance == 0 {
+		if mean == 0 {
+			return 1
+		}
+
+		return 0
+	}
+
+	size := float64(len(differences))
+	t := mean / math.Sqrt(variance/size)
+	degreesOfFreedom := size - 1
+
+	return regularizedIncompleteBeta(degreesOfFreedom/(degreesOfFreedom+t*t), degreesOfFreedom/2, 0.5)
+}
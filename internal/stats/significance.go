@@ -0,0 +1,297 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+)
+
+// Mann-Whitney U test p-value is computed using exact distribution for samples without ties that have at most
+// mannWhitneyExactMaxSize values in total, otherwise normal approximation is used.
+const mannWhitneyExactMaxSize = 40
+
+// Comparison contains two sided p-values of statistical tests for null hypothesis that LHS and RHS durations are
+// from same distribution. P-value is 1 if any side has less than 2 durations.
+type Comparison struct {
+	MannWhitneyPValue float64 `json:"mann_whitney_p_value"`
+	WelchPValue       float64 `json:"welch_p_value"`
+	// RelativeMedianDiffConfidenceInterval is confidence interval of (rhs - lhs) / lhs median difference in percents
+	RelativeMedianDiffConfidenceInterval ConfidenceInterval `json:"relative_median_diff_confidence_interval"`
+}
+
+// HasServerDurations returns true if any execution time has server duration, drivers that do not report server
+// durations leave them zero.
+func HasServerDurations(times []driver.ExecutionTime) bool {
+	return slices.ContainsFunc(times, func(t driver.ExecutionTime) bool {
+		return t.ServerDuration > 0
+	})
+}
+
+func CompareServerDurations(lhs []driver.ExecutionTime, rhs []driver.ExecutionTime) Comparison {
+	return compareDurations(lhs, rhs, func(t driver.ExecutionTime) time.Duration {
+		return t.ServerDuration
+	})
+}
+
+func CompareClientDurations(lhs []driver.ExecutionTime, rhs []driver.ExecutionTime) Comparison {
+	return compareDurations(lhs, rhs, func(t driver.ExecutionTime) time.Duration {
+		return t.ClientDuration
+	})
+}
+
+func compareDurations(lhs []driver.ExecutionTime,
+	rhs []driver.ExecutionTime,
+	getDuration func(executionTime driver.ExecutionTime) time.Duration,
+) Comparison {
+	lhsValues := getDurationValues(lhs, getDuration)
+	rhsValues := getDurationValues(rhs, getDuration)
+
+	return Comparison{
+		MannWhitneyPValue:                    MannWhitneyUTest(lhsValues, rhsValues),
+		WelchPValue:                          WelchTTest(lhsValues, rhsValues),
+		RelativeMedianDiffConfidenceInterval: GetRelativeMedianDiffConfidenceInterval(lhsValues, rhsValues),
+	}
+}
+
+// MannWhitneyUTest returns two sided p-value of Mann-Whitney U test.
+func MannWhitneyUTest(lhs []float64, rhs []float64) float64 {
+	lhsSize, rhsSize := len(lhs), len(rhs)
+	if lhsSize < 2 || rhsSize < 2 {
+		return 1
+	}
+
+	type rankedValue struct {
+		value float64
+		isLHS bool
+	}
+
+	values := make([]rankedValue, 0, lhsSize+rhsSize)
+	for _, value := range lhs {
+		values = append(values, rankedValue{value: value, isLHS: true})
+	}
+	for _, value := range rhs {
+		values = append(values, rankedValue{value: value})
+	}
+
+	slices.SortFunc(values, func(a, b rankedValue) int {
+		switch {
+		case a.value < b.value:
+			return -1
+		case a.value > b.value:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	// Tied values get average of their ranks
+	lhsRankSum := 0.0
+	tiesCorrection := 0.0
+
+	for start := 0; start < len(values); {
+		end := start + 1
+		for end < len(values) && values[end].value == values[start].value {
+			end++
+		}
+
+		averageRank := float64(start+end+1) / 2
+		for _, value := range values[start:end] {
+			if value.isLHS {
+				lhsRankSum += averageRank
+			}
+		}
+
+		tiesCount := float64(end - start)
+		tiesCorrection += tiesCount*tiesCount*tiesCount - tiesCount
+
+		start = end
+	}
+
+	u := lhsRankSum - float64(lhsSize*(lhsSize+1))/2
+
+	if tiesCorrection == 0 && lhsSize+rhsSize <= mannWhitneyExactMaxSize {
+		return mannWhitneyExactPValue(lhsSize, rhsSize, int(u))
+	}
+
+	totalSize := float64(lhsSize + rhsSize)
+	mean := float64(lhsSize*rhsSize) / 2
+	variance := float64(lhsSize*rhsSize) / 12 * (totalSize + 1 - tiesCorrection/(totalSize*(totalSize-1)))
+	if variance <= 0 {
+		return 1
+	}
+
+	// Continuity correction
+	z := math.Max(math.Abs(u-mean)-0.5, 0) / math.Sqrt(variance)
+
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// mannWhitneyExactPValue returns two sided p-value of U statistic using exact distribution of U for samples of
+// lhsSize and rhsSize values without ties.
+func mannWhitneyExactPValue(lhsSize int, rhsSize int, u int) float64 {
+	maxU := lhsSize * rhsSize
+
+	// counts[i][j][k] is number of orderings of i LHS and j RHS values with U statistic equal to k, orderings are
+	// built by appending largest value, that adds j to U if it is from LHS
+	counts := make([][][]float64, lhsSize+1)
+	for i := range counts {
+		counts[i] = make([][]float64, rhsSize+1)
+		for j := range counts[i] {
+			counts[i][j] = make([]float64, i*j+1)
+
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+
+			for k := range counts[i][j] {
+				if k-j >= 0 && k-j < len(counts[i-1][j]) {
+					counts[i][j][k] += counts[i-1][j][k-j]
+				}
+				if k < len(counts[i][j-1]) {
+					counts[i][j][k] += counts[i][j-1][k]
+				}
+			}
+		}
+	}
+
+	distribution := counts[lhsSize][rhsSize]
+
+	total := 0.0
+	for _, count := range distribution {
+		total += count
+	}
+
+	// Distribution is symmetric, so tail of smaller of U and maxU - U is used
+	u = min(u, maxU-u)
+
+	tail := 0.0
+	for k := 0; k <= u; k++ {
+		tail += distribution[k]
+	}
+
+	return math.Min(1, 2*tail/total)
+}
+
+// WelchTTest returns two sided p-value of Welch's unequal variances t-test.
+func WelchTTest(lhs []float64, rhs []float64) float64 {
+	if len(lhs) < 2 || len(rhs) < 2 {
+		return 1
+	}
+
+	lhsMean, lhsVariance := getMeanAndSampleVariance(lhs)
+	rhsMean, rhsVariance := getMeanAndSampleVariance(rhs)
+
+	lhsError := lhsVariance / float64(len(lhs))
+	rhsError := rhsVariance / float64(len(rhs))
+	standardError := lhsError + rhsError
+
+	if standardError == 0 {
+		if lhsMean == rhsMean {
+			return 1
+		}
+
+		return 0
+	}
+
+	t := (lhsMean - rhsMean) / math.Sqrt(standardError)
+	degreesOfFreedom := standardError * standardError /
+		(lhsError*lhsError/float64(len(lhs)-1) + rhsError*rhsError/float64(len(rhs)-1))
+
+	return regularizedIncompleteBeta(degreesOfFreedom/(degreesOfFreedom+t*t), degreesOfFreedom/2, 0.5)
+}
+
+// GetCoefficientOfVariation returns sample standard deviation of values in percents of mean.
+func GetCoefficientOfVariation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean, variance := getMeanAndSampleVariance(values)
+	if mean == 0 {
+		return 0
+	}
+
+	return math.Sqrt(variance) / math.Abs(mean) * 100
+}
+
+func getMeanAndSampleVariance(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	variance /= float64(len(values) - 1)
+
+	return mean, variance
+}
+
+// regularizedIncompleteBeta returns regularized incomplete beta function I_x(a, b) using continued fraction
+// representation evaluated with modified Lentz's method.
+func regularizedIncompleteBeta(x float64, a float64, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	// Continued fraction converges quickly for x < (a + 1) / (a + b + 2), otherwise symmetry relation is used
+	if x > (a+1)/(a+b+2) {
+		return 1 - regularizedIncompleteBeta(1-x, b, a)
+	}
+
+	lgammaA, _ := math.Lgamma(a)
+	lgammaB, _ := math.Lgamma(b)
+	lgammaAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgammaAB-lgammaA-lgammaB+a*math.Log(x)+b*math.Log(1-x)) / a
+
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+
+	f, c, d := 1.0, 1.0, 0.0
+
+	for i := 0; i <= maxIterations; i++ {
+		m := float64(i / 2)
+
+		var numerator float64
+		switch {
+		case i == 0:
+			numerator = 1
+		case i%2 == 0:
+			numerator = m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		default:
+			numerator = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		}
+
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		d = 1 / d
+
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+
+		delta := c * d
+		f *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+
+	return front * (f - 1)
+}
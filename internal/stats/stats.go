@@ -16,6 +16,9 @@ type Stats struct {
 	MedianServerDuration     time.Duration `json:"median_server_duration"`
 	DispersionServerDuration time.Duration `json:"dispersion_server_duration"`
 	StdDevServerDuration     time.Duration `json:"std_dev_server_duration"`
+	// MedianServerDurationConfidenceInterval is bootstrap confidence interval of median server duration in nanoseconds
+	MedianServerDurationConfidenceInterval ConfidenceInterval `json:"median_server_duration_confidence_interval"`
+	ServerDurationDistribution             DistributionStats  `json:"server_duration_distribution"`
 
 	MinClientDuration        time.Duration `json:"min_client_duration"`
 	MaxClientDuration        time.Duration `json:"max_client_duration"`
@@ -23,6 +26,17 @@ type Stats struct {
 	MedianClientDuration     time.Duration `json:"median_client_duration"`
 	DispersionClientDuration time.Duration `json:"dispersion_client_duration"`
 	StdDevClientDuration     time.Duration `json:"std_dev_client_duration"`
+	// MedianClientDurationConfidenceInterval is bootstrap confidence interval of median client duration in nanoseconds
+	MedianClientDurationConfidenceInterval ConfidenceInterval `json:"median_client_duration_confidence_interval"`
+	ClientDurationDistribution             DistributionStats  `json:"client_duration_distribution"`
+
+	Metrics map[string]MetricStats `json:"metrics"`
+}
+
+type MetricStats struct {
+	Min    uint64 `json:"min"`
+	Max    uint64 `json:"max"`
+	Median uint64 `json:"median"`
 }
 
 func (s *Stats) GetMinServerDurationMilliseconds() float64 {
@@ -45,6 +59,10 @@ func (s *Stats) GetStdDevServerDurationMilliseconds() float64 {
 	return float64(s.StdDevServerDuration) / 1e6
 }
 
+func (s *Stats) GetMedianServerDurationConfidenceIntervalMilliseconds() ConfidenceInterval {
+	return s.MedianServerDurationConfidenceInterval.toMilliseconds()
+}
+
 func (s *Stats) GetMinClientDurationMilliseconds() float64 {
 	return float64(s.MinClientDuration) / 1e6
 }
@@ -65,6 +83,10 @@ func (s *Stats) GetStdDevClientDurationMilliseconds() float64 {
 	return float64(s.StdDevClientDuration) / 1e6
 }
 
+func (s *Stats) GetMedianClientDurationConfidenceIntervalMilliseconds() ConfidenceInterval {
+	return s.MedianClientDurationConfidenceInterval.toMilliseconds()
+}
+
 func GetStats(times []driver.ExecutionTime) Stats {
 	result := Stats{}
 
@@ -123,9 +145,53 @@ func GetStats(times []driver.ExecutionTime) Stats {
 		return t.ClientDuration
 	})
 
+	result.MedianServerDurationConfidenceInterval = GetMedianConfidenceInterval(getDurationValues(times,
+		func(t driver.ExecutionTime) time.Duration {
+			return t.ServerDuration
+		},
+	))
+	result.MedianClientDurationConfidenceInterval = GetMedianConfidenceInterval(getDurationValues(times,
+		func(t driver.ExecutionTime) time.Duration {
+			return t.ClientDuration
+		},
+	))
+
+	result.ServerDurationDistribution = getDistributionStats(times, func(t driver.ExecutionTime) time.Duration {
+		return t.ServerDuration
+	})
+	result.ClientDurationDistribution = getDistributionStats(times, func(t driver.ExecutionTime) time.Duration {
+		return t.ClientDuration
+	})
+
+	result.Metrics = getMetricsStats(times)
+
 	return result
 }
 
+func getMetricsStats(times []driver.ExecutionTime) map[string]MetricStats {
+	metricValues := map[string][]uint64{}
+	for _, t := range times {
+		for name, value := range t.Metrics {
+			metricValues[name] = append(metricValues[name], value)
+		}
+	}
+
+	metrics := make(map[string]MetricStats, len(metricValues))
+	for name, values := range metricValues {
+		slices.Sort(values)
+
+		median := values[len(values)/2]
+		if len(values)%2 == 0 {
+			lower := values[len(values)/2-1]
+			median = lower + (median-lower)/2
+		}
+
+		metrics[name] = MetricStats{Min: values[0], Max: values[len(values)-1], Median: median}
+	}
+
+	return metrics
+}
+
 func getMedianDuration(
 	times []driver.ExecutionTime,
 	getDuration func(executionTime driver.ExecutionTime) time.Duration,
@@ -147,3 +213,81 @@ func getMedianDuration(
 
 	return durations[mid]
 }
+
+type LoadStats struct {
+	Runs       uint64  `json:"runs"`
+	Errors     uint64  `json:"errors"`
+	Throughput float64 `json:"throughput"`
+
+	MinClientDuration    time.Duration `json:"min_client_duration"`
+	MedianClientDuration time.Duration `json:"median_client_duration"`
+	P90ClientDuration    time.Duration `json:"p90_client_duration"`
+	P99ClientDuration    time.Duration `json:"p99_client_duration"`
+	MaxClientDuration    time.Duration `json:"max_client_duration"`
+}
+
+func (s *LoadStats) GetMinClientDurationMilliseconds() float64 {
+	return float64(s.MinClientDuration) / 1e6
+}
+
+func (s *LoadStats) GetMedianClientDurationMilliseconds() float64 {
+	return float64(s.MedianClientDuration) / 1e6
+}
+
+func (s *LoadStats) GetP90ClientDurationMilliseconds() float64 {
+	return float64(s.P90ClientDuration) / 1e6
+}
+
+func (s *LoadStats) GetP99ClientDurationMilliseconds() float64 {
+	return float64(s.P99ClientDuration) / 1e6
+}
+
+func (s *LoadStats) GetMaxClientDurationMilliseconds() float64 {
+	return float64(s.MaxClientDuration) / 1e6
+}
+
+// GetLoadStats returns throughput and client latency percentiles of concurrent load phase
+// that executed times successfully and failed errors times during duration.
+func GetLoadStats(times []driver.ExecutionTime, errors uint64, duration time.Duration) LoadStats {
+	result := LoadStats{
+		Runs:   uint64(len(times)),
+		Errors: errors,
+	}
+
+	if len(times) == 0 {
+		return result
+	}
+
+	if duration > 0 {
+		result.Throughput = float64(len(times)) / duration.Seconds()
+	}
+
+	durations := make([]time.Duration, len(times))
+	for i, t := range times {
+		durations[i] = t.ClientDuration
+	}
+	slices.Sort(durations)
+
+	result.MinClientDuration = durations[0]
+	result.MedianClientDuration = getPercentileDuration(durations, 50)
+	result.P90ClientDuration = getPercentileDuration(durations, 90)
+	result.P99ClientDuration = getPercentileDuration(durations, 99)
+	result.MaxClientDuration = durations[len(durations)-1]
+
+	return result
+}
+
+// getPercentileDuration returns percentile of sorted durations using linear interpolation between closest ranks.
+func getPercentileDuration(sortedDurations []time.Duration, percentile float64) time.Duration {
+	if len(sortedDurations) == 0 {
+		return 0
+	}
+
+	rank := percentile / 100 * float64(len(sortedDurations)-1)
+	lowerIndex := int(math.Floor(rank))
+	upperIndex := int(math.Ceil(rank))
+
+	lower, upper := sortedDurations[lowerIndex], sortedDurations[upperIndex]
+
+	return lower + time.Duration(float64(upper-lower)*(rank-float64(lowerIndex)))
+}
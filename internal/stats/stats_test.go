@@ -1,6 +1,7 @@
 package stats_test
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -47,3 +48,304 @@ func TestGetEmptyStats(t *testing.T) {
 	require.Equal(t, stats.MeanClientDuration, time.Duration(0))
 	require.Equal(t, stats.MedianClientDuration, time.Duration(0))
 }
+
+func TestGetMetricsStats(t *testing.T) {
+	executionTimes := []driver.ExecutionTime{
+		{Metrics: map[string]uint64{"read_rows": 10, "memory_usage": 300}},
+		{Metrics: map[string]uint64{"read_rows": 30, "memory_usage": 100}},
+		{Metrics: map[string]uint64{"read_rows": 20}},
+		{},
+	}
+
+	result := stats.GetStats(executionTimes)
+
+	require.Equal(t, result.Metrics["read_rows"], stats.MetricStats{Min: 10, Max: 30, Median: 20})
+	require.Equal(t, result.Metrics["memory_usage"], stats.MetricStats{Min: 100, Max: 300, Median: 200})
+}
+
+func TestGetLoadStats(t *testing.T) {
+	executionTimes := []driver.ExecutionTime{}
+
+	for i := range 101 {
+		executionTimes = append(executionTimes, driver.ExecutionTime{
+			ClientDuration: time.Millisecond * time.Duration(100-i),
+		})
+	}
+
+	result := stats.GetLoadStats(executionTimes, 3, 10*time.Second)
+
+	require.Equal(t, uint64(101), result.Runs)
+	require.Equal(t, uint64(3), result.Errors)
+	require.InDelta(t, 10.1, result.Throughput, 1e-9)
+	require.Equal(t, time.Duration(0), result.MinClientDuration)
+	require.Equal(t, time.Millisecond*50, result.MedianClientDuration)
+	require.Equal(t, time.Millisecond*90, result.P90ClientDuration)
+	require.Equal(t, time.Millisecond*99, result.P99ClientDuration)
+	require.Equal(t, time.Millisecond*100, result.MaxClientDuration)
+}
+
+func TestMannWhitneyUTest(t *testing.T) {
+	// Exact distribution, all LHS values are smaller than RHS values, so p-value is 2 / C(10, 5)
+	require.InDelta(t, 2.0/252.0, stats.MannWhitneyUTest([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}),
+		1e-12)
+	require.InDelta(t, 2.0/252.0, stats.MannWhitneyUTest([]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}), 1e-12)
+	require.InDelta(t,
+		52.0/126.0,
+		stats.MannWhitneyUTest([]float64{1.1, 2.3, 3.2, 4.8, 5.5}, []float64{2.2, 3.3, 6.1, 7.4}),
+		1e-12,
+	)
+
+	// Normal approximation with ties correction
+	require.InDelta(t, 0.113846, stats.MannWhitneyUTest([]float64{1, 2, 3, 4, 5}, []float64{3, 4, 5, 6, 7}), 1e-6)
+
+	require.InDelta(t, 1.0, stats.MannWhitneyUTest([]float64{1, 1, 1}, []float64{1, 1, 1}), 1e-12)
+	require.InDelta(t, 1.0, stats.MannWhitneyUTest([]float64{1}, []float64{2, 3}), 1e-12)
+}
+
+func TestWelchTTest(t *testing.T) {
+	require.InDelta(t, 0.107531, stats.WelchTTest([]float64{1, 2, 3, 4, 5}, []float64{2, 4, 6, 8, 10}), 1e-5)
+	require.InDelta(t, 0.120066, stats.WelchTTest([]float64{10, 11, 12, 13, 14, 15}, []float64{12, 13, 15, 16, 18}), 1e-5)
+
+	require.InDelta(t, 1.0, stats.WelchTTest([]float64{1, 1, 1}, []float64{1, 1, 1}), 1e-12)
+	require.InDelta(t, 0.0, stats.WelchTTest([]float64{1, 1, 1}, []float64{2, 2, 2}), 1e-12)
+	require.InDelta(t, 1.0, stats.WelchTTest([]float64{1}, []float64{2, 3}), 1e-12)
+}
+
+func TestWilcoxonSignedRankTest(t *testing.T) {
+	require.InDelta(t, 0.0625, stats.WilcoxonSignedRankTest([]float64{1, 2, 3, 4, 5}, []float64{2, 4, 6, 8, 10}), 1e-12)
+	require.InDelta(t, 0.195313,
+		stats.WilcoxonSignedRankTest([]float64{0, 0, 0, 0, 0, 0, 0, 0}, []float64{1, 2, 3, 4, 5, 6, 7, -8}),
+		1e-5,
+	)
+
+	// Pairs with zero difference are dropped
+	require.InDelta(t, 0.0625,
+		stats.WilcoxonSignedRankTest([]float64{1, 2, 3, 4, 5, 6}, []float64{2, 4, 6, 8, 10, 6}),
+		1e-12,
+	)
+
+	require.InDelta(t, 1.0, stats.WilcoxonSignedRankTest([]float64{1, 2, 3}, []float64{1, 2, 3}), 1e-12)
+	require.InDelta(t, 1.0, stats.WilcoxonSignedRankTest([]float64{1, 2, 3}, []float64{1, 2}), 1e-12)
+}
+
+func TestPairedTTest(t *testing.T) {
+	require.InDelta(t, 0.013236, stats.PairedTTest([]float64{1, 2, 3, 4, 5}, []float64{2, 4, 6, 8, 10}), 1e-5)
+
+	require.InDelta(t, 1.0, stats.PairedTTest([]float64{1, 2, 3}, []float64{1, 2, 3}), 1e-12)
+	require.InDelta(t, 0.0, stats.PairedTTest([]float64{1, 2, 3}, []float64{2, 3, 4}), 1e-12)
+	require.InDelta(t, 1.0, stats.PairedTTest([]float64{1}, []float64{2}), 1e-12)
+}
+
+func TestComparePairedServerDurations(t *testing.T) {
+	lhs := []driver.ExecutionTime{{ServerDuration: 10}, {ServerDuration: 11}, {ServerDuration: 12}}
+	rhs := []driver.ExecutionTime{{ServerDuration: 20}, {ServerDuration: 22}}
+
+	result := stats.ComparePairedServerDurations(lhs, rhs)
+	require.Equal(t, 2, result.Pairs)
+	require.InDelta(t, 0.5, result.WilcoxonSignedRankPValue, 1e-12)
+}
+
+func TestGetMedianConfidenceInterval(t *testing.T) {
+	result := stats.GetMedianConfidenceInterval([]float64{5, 5, 5})
+	require.Equal(t, stats.ConfidenceInterval{Lower: 5, Upper: 5, Valid: true}, result)
+
+	values := []float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 100}
+	result = stats.GetMedianConfidenceInterval(values)
+	require.LessOrEqual(t, result.Lower, 14.5)
+	require.GreaterOrEqual(t, result.Upper, 14.5)
+	require.GreaterOrEqual(t, result.Lower, 10.0)
+	require.LessOrEqual(t, result.Upper, 18.0)
+
+	// Same values produce same interval
+	require.Equal(t, result, stats.GetMedianConfidenceInterval(values))
+
+	require.False(t, stats.GetMedianConfidenceInterval(nil).Valid)
+}
+
+func TestGetRelativeMedianDiffConfidenceInterval(t *testing.T) {
+	result := stats.GetRelativeMedianDiffConfidenceInterval([]float64{10, 10, 10}, []float64{12, 12, 12})
+	require.InDelta(t, 20.0, result.Lower, 1e-9)
+	require.InDelta(t, 20.0, result.Upper, 1e-9)
+
+	result = stats.GetRelativeMedianDiffConfidenceInterval([]float64{10, 11, 12, 13, 14}, []float64{20, 22, 24, 26, 28})
+	require.Greater(t, result.Lower, 0.0)
+	require.LessOrEqual(t, result.Lower, 100.0)
+	require.GreaterOrEqual(t, result.Upper, 100.0)
+	require.Equal(t, "[+", result.FormatBounds("%+.2f%%")[:2])
+
+	// Relative difference to zero median is not defined
+	result = stats.GetRelativeMedianDiffConfidenceInterval([]float64{0, 0, 0}, []float64{12, 12, 12})
+	require.False(t, result.Valid)
+	require.Equal(t, "-", result.FormatBounds("%+.2f%%"))
+
+	result = stats.GetRelativeMedianDiffConfidenceInterval([]float64{0, 10, 10}, []float64{12, 12, 12})
+	require.False(t, result.Valid)
+
+	executionTimes := []driver.ExecutionTime{
+		{ServerDuration: time.Millisecond, ClientDuration: 2 * time.Millisecond},
+		{ServerDuration: time.Millisecond, ClientDuration: 2 * time.Millisecond},
+	}
+	statsResult := stats.GetStats(executionTimes)
+	require.Equal(t,
+		stats.ConfidenceInterval{Lower: 1, Upper: 1, Valid: true},
+		statsResult.GetMedianServerDurationConfidenceIntervalMilliseconds(),
+	)
+	require.Equal(t,
+		stats.ConfidenceInterval{Lower: 2, Upper: 2, Valid: true},
+		statsResult.GetMedianClientDurationConfidenceIntervalMilliseconds(),
+	)
+}
+
+func TestGetCoefficientOfVariation(t *testing.T) {
+	require.InDelta(t, 50.0, stats.GetCoefficientOfVariation([]float64{1, 2, 3}), 1e-9)
+	require.InDelta(t, 0.0, stats.GetCoefficientOfVariation([]float64{5, 5, 5}), 1e-9)
+	require.InDelta(t, 0.0, stats.GetCoefficientOfVariation([]float64{5}), 1e-9)
+}
+
+func TestGetRelativeMedianConfidenceIntervalWidth(t *testing.T) {
+	require.InDelta(t, 0.0, stats.GetRelativeMedianConfidenceIntervalWidth([]float64{5, 5, 5}), 1e-9)
+	require.InDelta(t, 0.0, stats.GetRelativeMedianConfidenceIntervalWidth(nil), 1e-9)
+
+	values := []float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 100}
+	confidenceInterval := stats.GetMedianConfidenceInterval(values)
+	require.InDelta(t,
+		(confidenceInterval.Upper-confidenceInterval.Lower)/14.5*100,
+		stats.GetRelativeMedianConfidenceIntervalWidth(values),
+		1e-9,
+	)
+}
+
+func TestGetDistributionStats(t *testing.T) {
+	executionTimes := []driver.ExecutionTime{}
+
+	for i := range 10 {
+		executionTimes = append(executionTimes, driver.ExecutionTime{ServerDuration: time.Second * time.Duration(i+1)})
+	}
+	executionTimes = append(executionTimes, driver.ExecutionTime{ServerDuration: time.Second * 100})
+
+	result := stats.GetStats(executionTimes).ServerDurationDistribution
+
+	require.Equal(t, time.Second*10, result.P90Duration)
+	require.Equal(t, time.Second*55, result.P95Duration)
+	require.Equal(t, time.Second*91, result.P99Duration)
+	require.Equal(t, time.Second*3, result.MADDuration)
+
+	expectedOutliers := make([]bool, 11)
+	expectedOutliers[10] = true
+	require.Equal(t, expectedOutliers, result.Outliers)
+	require.Equal(t, 1, result.GetOutliersCount())
+
+	require.Equal(t, time.Second, result.Histogram.Min)
+	require.Equal(t, time.Millisecond*4950, result.Histogram.BucketWidth)
+	require.Len(t, result.Histogram.Counts, 20)
+	require.Equal(t, uint64(5), result.Histogram.Counts[0])
+	require.Equal(t, uint64(5), result.Histogram.Counts[1])
+	require.Equal(t, uint64(1), result.Histogram.Counts[19])
+
+	result = stats.GetStats(executionTimes[:1]).ServerDurationDistribution
+	require.Equal(t, []uint64{1}, result.Histogram.Counts)
+	require.Equal(t, []bool{false}, result.Outliers)
+}
+
+func TestClassifyChange(t *testing.T) {
+	significant := stats.Comparison{MannWhitneyPValue: 0.01}
+	notSignificant := stats.Comparison{MannWhitneyPValue: 0.5}
+
+	require.Equal(t, stats.ChangeRegression, stats.ClassifyChange(10, significant, 0.05, 2))
+	require.Equal(t, stats.ChangeImprovement, stats.ClassifyChange(-10, significant, 0.05, 2))
+	require.Equal(t, stats.ChangeUnchanged, stats.ClassifyChange(1, significant, 0.05, 2))
+	require.Equal(t, stats.ChangeUnchanged, stats.ClassifyChange(10, notSignificant, 0.05, 2))
+}
+
+func TestGetSuiteSummary(t *testing.T) {
+	getExecutionTimes := func(startMilliseconds int) []driver.ExecutionTime {
+		executionTimes := []driver.ExecutionTime{}
+		for i := range 5 {
+			executionTimes = append(executionTimes, driver.ExecutionTime{
+				ServerDuration: time.Millisecond * time.Duration(startMilliseconds+i),
+			})
+		}
+
+		return executionTimes
+	}
+
+	queries := []stats.SuiteQuery{
+		{QueryNumber: 0, LHSTimes: getExecutionTimes(10), RHSTimes: getExecutionTimes(20)},
+		{QueryNumber: 1, ID: "improved", LHSTimes: getExecutionTimes(20), RHSTimes: getExecutionTimes(10)},
+		{QueryNumber: 2, LHSTimes: getExecutionTimes(5), RHSTimes: getExecutionTimes(5)},
+	}
+
+	result := stats.GetSuiteSummary(queries, 0.05, 2, 5)
+
+	require.Equal(t, 3, result.Queries)
+	require.Equal(t, stats.ComparedDurationServer, result.ComparedDuration)
+	require.InDelta(t, 1.0, result.GeometricMeanRatio, 1e-9)
+	require.LessOrEqual(t, result.GeometricMeanRatioConfidenceInterval.Lower, 1.0)
+	require.GreaterOrEqual(t, result.GeometricMeanRatioConfidenceInterval.Upper, 1.0)
+	require.Equal(t, time.Millisecond*41, result.LHSTotalDuration)
+	require.Equal(t, time.Millisecond*41, result.RHSTotalDuration)
+	require.Equal(t, 1, result.Improvements)
+	require.Equal(t, 1, result.Regressions)
+	require.Equal(t, 1, result.Unchanged)
+
+	require.Len(t, result.TopRegressions, 1)
+	require.Equal(t, 0, result.TopRegressions[0].QueryNumber)
+	require.InDelta(t, 22.0/12.0*100-100, result.TopRegressions[0].RelativeDiff, 1e-9)
+
+	require.Len(t, result.TopImprovements, 1)
+	require.Equal(t, 1, result.TopImprovements[0].QueryNumber)
+	require.Equal(t, "improved", result.TopImprovements[0].GetName())
+	require.Equal(t, "0", result.TopRegressions[0].GetName())
+
+	result = stats.GetSuiteSummary(queries, 0.05, 2, 0)
+	require.Empty(t, result.TopRegressions)
+	require.Empty(t, result.TopImprovements)
+}
+
+func TestGetSuiteSummaryClientDurations(t *testing.T) {
+	getExecutionTimes := func(startMilliseconds int) []driver.ExecutionTime {
+		executionTimes := []driver.ExecutionTime{}
+		for i := range 5 {
+			executionTimes = append(executionTimes, driver.ExecutionTime{
+				ClientDuration: time.Millisecond * time.Duration(startMilliseconds+i),
+			})
+		}
+
+		return executionTimes
+	}
+
+	require.False(t, stats.HasServerDurations(getExecutionTimes(10)))
+	require.True(t, stats.HasServerDurations([]driver.ExecutionTime{{}, {ServerDuration: time.Millisecond}}))
+
+	// Driver does not report server durations, client durations are compared
+	queries := []stats.SuiteQuery{
+		{QueryNumber: 0, LHSTimes: getExecutionTimes(10), RHSTimes: getExecutionTimes(20)},
+		{QueryNumber: 1, LHSTimes: getExecutionTimes(10), RHSTimes: getExecutionTimes(10)},
+	}
+
+	result := stats.GetSuiteSummary(queries, 0.05, 2, 5)
+
+	require.Equal(t, time.Millisecond*24, result.LHSTotalDuration)
+	require.Equal(t, time.Millisecond*34, result.RHSTotalDuration)
+	require.InDelta(t, math.Sqrt(22.0/12.0), result.GeometricMeanRatio, 1e-9)
+	require.True(t, result.GeometricMeanRatioConfidenceInterval.Valid)
+	require.Greater(t, result.GeometricMeanRatioConfidenceInterval.Upper, 1.0)
+	require.Equal(t, 1, result.Regressions)
+	require.Equal(t, 1, result.Unchanged)
+
+	require.Len(t, result.TopRegressions, 1)
+	require.Equal(t, 0, result.TopRegressions[0].QueryNumber)
+	require.InDelta(t, 22.0/12.0*100-100, result.TopRegressions[0].RelativeDiff, 1e-9)
+	require.Less(t, result.TopRegressions[0].PValue, 0.05)
+	require.Equal(t, stats.ComparedDurationClient, result.ComparedDuration)
+
+	// Queries that are compared by different durations make suite compared duration mixed
+	queries = append(queries, stats.SuiteQuery{
+		QueryNumber: 2,
+		LHSTimes:    []driver.ExecutionTime{{ServerDuration: time.Millisecond, ClientDuration: time.Millisecond}},
+		RHSTimes:    []driver.ExecutionTime{{ServerDuration: time.Millisecond, ClientDuration: time.Millisecond}},
+	})
+
+	result = stats.GetSuiteSummary(queries, 0.05, 2, 5)
+	require.Equal(t, stats.ComparedDurationMixed, result.ComparedDuration)
+}
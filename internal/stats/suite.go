@@ -0,0 +1,267 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kitaisreal/paw/internal/driver"
+)
+
+type Change string
+
+const (
+	ChangeUnchanged   Change = "unchanged"
+	ChangeImprovement Change = "improvement"
+	ChangeRegression  Change = "regression"
+)
+
+// ClassifyChange returns improvement or regression if difference is statistically significant according to
+// Mann-Whitney U test and relative difference in percents is at least minRelativeDiff, otherwise unchanged.
+func ClassifyChange(relativeDiff float64,
+	comparison Comparison,
+	significanceLevel float64,
+	minRelativeDiff float64,
+) Change {
+	if comparison.MannWhitneyPValue >= significanceLevel || math.Abs(relativeDiff) < minRelativeDiff {
+		return ChangeUnchanged
+	}
+
+	if relativeDiff > 0 {
+		return ChangeRegression
+	}
+
+	return ChangeImprovement
+}
+
+// ComparedDuration is execution time duration that is compared, server durations are compared if both sides have
+// them, otherwise client durations.
+type ComparedDuration string
+
+const (
+	ComparedDurationServer ComparedDuration = "server"
+	ComparedDurationClient ComparedDuration = "client"
+	// ComparedDurationMixed is compared duration of suite in which some queries are compared by server durations and
+	// others by client durations
+	ComparedDurationMixed ComparedDuration = "mixed"
+)
+
+// GetComparedDuration returns server if both lhs and rhs have server durations, otherwise client.
+func GetComparedDuration(lhs []driver.ExecutionTime, rhs []driver.ExecutionTime) ComparedDuration {
+	if HasServerDurations(lhs) && HasServerDurations(rhs) {
+		return ComparedDurationServer
+	}
+
+	return ComparedDurationClient
+}
+
+// SuiteQuery contains LHS and RHS execution times of one suite query, ID is empty if query has no id.
+type SuiteQuery struct {
+	QueryNumber int
+	ID          string
+	Query       string
+	LHSTimes    []driver.ExecutionTime
+	RHSTimes    []driver.ExecutionTime
+}
+
+type QueryChange struct {
+	QueryNumber  int     `json:"query_number"`
+	ID           string  `json:"id,omitempty"`
+	Query        string  `json:"query"`
+	RelativeDiff float64 `json:"relative_diff"`
+	PValue       float64 `json:"p_value"`
+	Change       Change  `json:"change"`
+}
+
+// GetName returns query id if it is specified, otherwise query number.
+func (c QueryChange) GetName() string {
+	if c.ID != "" {
+		return c.ID
+	}
+
+	return strconv.Itoa(c.QueryNumber)
+}
+
+// SuiteSummary summarizes duration differences of all suite queries, server durations are compared if both sides
+// have them, otherwise client durations. Ratio is RHS median divided by LHS median, so ratio below 1 is improvement.
+// Queries with zero median on any side are not included in geometric mean.
+type SuiteSummary struct {
+	Queries                              int                `json:"queries"`
+	ComparedDuration                     ComparedDuration   `json:"compared_duration,omitempty"`
+	GeometricMeanRatio                   float64            `json:"geometric_mean_ratio"`
+	GeometricMeanRatioConfidenceInterval ConfidenceInterval `json:"geometric_mean_ratio_confidence_interval"`
+	LHSTotalDuration                     time.Duration      `json:"lhs_total_duration"`
+	RHSTotalDuration                     time.Duration      `json:"rhs_total_duration"`
+	Improvements                         int                `json:"improvements"`
+	Regressions                          int                `json:"regressions"`
+	Unchanged                            int                `json:"unchanged"`
+	QueryChanges                         []QueryChange      `json:"query_changes"`
+	TopRegressions                       []QueryChange      `json:"top_regressions"`
+	TopImprovements                      []QueryChange      `json:"top_improvements"`
+}
+
+func (s *SuiteSummary) GetLHSTotalDurationMilliseconds() float64 {
+	return float64(s.LHSTotalDuration) / 1e6
+}
+
+func (s *SuiteSummary) GetRHSTotalDurationMilliseconds() float64 {
+	return float64(s.RHSTotalDuration) / 1e6
+}
+
+func (s *SuiteSummary) GetRelativeTotalDurationDiff() float64 {
+	if s.LHSTotalDuration == 0 {
+		return 0
+	}
+
+	return float64(s.RHSTotalDuration-s.LHSTotalDuration) / float64(s.LHSTotalDuration) * 100
+}
+
+// GetSuiteSummary returns summary of suite queries durations differences, at most topQueries largest
+// significant regressions and improvements are returned.
+func GetSuiteSummary(queries []SuiteQuery,
+	significanceLevel float64,
+	minRelativeDiff float64,
+	topQueries int,
+) SuiteSummary {
+	result := SuiteSummary{Queries: len(queries), QueryChanges: []QueryChange{}}
+
+	ratioQueries := []SuiteQuery{}
+	logRatioSum := 0.0
+
+	for _, query := range queries {
+		switch comparedDuration := GetComparedDuration(query.LHSTimes, query.RHSTimes); result.ComparedDuration {
+		case "":
+			result.ComparedDuration = comparedDuration
+		case comparedDuration:
+		default:
+			result.ComparedDuration = ComparedDurationMixed
+		}
+
+		getDuration := getComparedDuration(query.LHSTimes, query.RHSTimes)
+		lhsMedian := getMedianDuration(query.LHSTimes, getDuration)
+		rhsMedian := getMedianDuration(query.RHSTimes, getDuration)
+
+		result.LHSTotalDuration += lhsMedian
+		result.RHSTotalDuration += rhsMedian
+
+		if lhsMedian > 0 && rhsMedian > 0 {
+			ratioQueries = append(ratioQueries, query)
+			logRatioSum += math.Log(float64(rhsMedian) / float64(lhsMedian))
+		}
+
+		relativeDiff := 0.0
+		if lhsMedian > 0 {
+			relativeDiff = float64(rhsMedian-lhsMedian) / float64(lhsMedian) * 100
+		}
+
+		comparison := compareDurations(query.LHSTimes, query.RHSTimes, getDuration)
+		queryChange := QueryChange{
+			QueryNumber:  query.QueryNumber,
+			ID:           query.ID,
+			Query:        query.Query,
+			RelativeDiff: relativeDiff,
+			PValue:       comparison.MannWhitneyPValue,
+			Change:       ClassifyChange(relativeDiff, comparison, significanceLevel, minRelativeDiff),
+		}
+
+		switch queryChange.Change {
+		case ChangeImprovement:
+			result.Improvements++
+			result.TopImprovements = append(result.TopImprovements, queryChange)
+		case ChangeRegression:
+			result.Regressions++
+			result.TopRegressions = append(result.TopRegressions, queryChange)
+		case ChangeUnchanged:
+			result.Unchanged++
+		}
+
+		result.QueryChanges = append(result.QueryChanges, queryChange)
+	}
+
+	if len(ratioQueries) > 0 {
+		result.GeometricMeanRatio = math.Exp(logRatioSum / float64(len(ratioQueries)))
+		result.GeometricMeanRatioConfidenceInterval = getGeometricMeanRatioConfidenceInterval(ratioQueries)
+	}
+
+	sortQueryChanges := func(queryChanges []QueryChange) []QueryChange {
+		slices.SortStableFunc(queryChanges, func(a, b QueryChange) int {
+			switch {
+			case math.Abs(a.RelativeDiff) > math.Abs(b.RelativeDiff):
+				return -1
+			case math.Abs(a.RelativeDiff) < math.Abs(b.RelativeDiff):
+				return 1
+			default:
+				return 0
+			}
+		})
+
+		return queryChanges[:min(len(queryChanges), topQueries)]
+	}
+
+	result.TopRegressions = sortQueryChanges(result.TopRegressions)
+	result.TopImprovements = sortQueryChanges(result.TopImprovements)
+
+	return result
+}
+
+// getGeometricMeanRatioConfidenceInterval returns bootstrap confidence interval of geometric mean of median ratios,
+// runs of each query are resampled independently, so interval reflects measurement noise of each query.
+func getGeometricMeanRatioConfidenceInterval(queries []SuiteQuery) ConfidenceInterval {
+	rng := newBootstrapRand()
+
+	type queryValues struct {
+		lhs         []float64
+		rhs         []float64
+		lhsResample []float64
+		rhsResample []float64
+	}
+
+	values := make([]queryValues, len(queries))
+	for i, query := range queries {
+		getDuration := getComparedDuration(query.LHSTimes, query.RHSTimes)
+		values[i] = queryValues{
+			lhs:         getDurationValues(query.LHSTimes, getDuration),
+			rhs:         getDurationValues(query.RHSTimes, getDuration),
+			lhsResample: make([]float64, len(query.LHSTimes)),
+			rhsResample: make([]float64, len(query.RHSTimes)),
+		}
+	}
+
+	geometricMeans := make([]float64, bootstrapResamples)
+
+	for i := range geometricMeans {
+		logRatioSum := 0.0
+
+		for _, value := range values {
+			lhsMedian := getResampleMedian(rng, value.lhs, value.lhsResample)
+			rhsMedian := getResampleMedian(rng, value.rhs, value.rhsResample)
+
+			// Resample can contain only zero durations, even if median of all durations is not zero
+			logRatioSum += math.Log(math.Max(rhsMedian, 1) / math.Max(lhsMedian, 1))
+		}
+
+		geometricMeans[i] = math.Exp(logRatioSum / float64(len(values)))
+	}
+
+	return getPercentileConfidenceInterval(geometricMeans)
+}
+
+// getComparedDuration returns server duration if both lhs and rhs have server durations, otherwise client duration.
+func getComparedDuration(lhs []driver.ExecutionTime,
+	rhs []driver.ExecutionTime,
+) func(executionTime driver.ExecutionTime) time.Duration {
+	if GetComparedDuration(lhs, rhs) == ComparedDurationServer {
+		return getServerDuration
+	}
+
+	return getClientDuration
+}
+
+func getServerDuration(t driver.ExecutionTime) time.Duration {
+	return t.ServerDuration
+}
+
+func getClientDuration(t driver.ExecutionTime) time.Duration {
+	return t.ClientDuration
+}